@@ -0,0 +1,40 @@
+package main
+
+import (
+	"github.com/bondar-pavel/gravity/physics"
+	"github.com/bondar-pavel/gravity/sandbox"
+)
+
+// perform applies an action to the world and the input state, logging it
+// first when a session is being recorded. The polling code turns ebiten
+// input into actions; replay feeds recorded actions in directly.
+func (s *InputState) perform(world *physics.World, a sandbox.Action) {
+	if s.recording != nil {
+		a.Tick = world.Tick()
+		s.recording.Actions = append(s.recording.Actions, a)
+	}
+	if a.Apply(world) {
+		if a.Kind == sandbox.ActionPlace {
+			s.placed(world, a)
+		}
+		return
+	}
+
+	switch a.Kind {
+	case sandbox.ActionSpeed:
+		s.simSpeed = a.Value
+	case sandbox.ActionToggleField:
+		s.showField = !s.showField
+	case sandbox.ActionToggleTrajectories:
+		s.showTrajectories = !s.showTrajectories
+	case sandbox.ActionToggleAccretion:
+		s.showAccretion = !s.showAccretion
+	case sandbox.ActionToggleDiagnostics:
+		s.showDiagnostics = !s.showDiagnostics
+		s.updateTracking(world)
+	case sandbox.ActionToggleGraphs:
+		s.showGraphs = !s.showGraphs
+		s.graphHistory.Clear()
+		s.updateTracking(world)
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"math"
+
+	"github.com/bondar-pavel/gravity/physics"
+)
+
+// Black hole rendering, in horizon radii on screen unless noted.
+const (
+	lensEinstein   = 2.5 // Einstein radius of the lens
+	lensReach      = 3   // lensed region, in Einstein radii
+	photonRing     = 1.5 // radius of the photon ring
+	accretionReach = 3   // outer edge of the accretion glow
+	accretionScale = 100 // mass swallowed recently that makes the glow fully bright
+)
+
+var (
+	photonRingColor = [3]float64{150, 170, 255}
+	accretionColor  = [3]float64{255, 140, 40}
+)
+
+// drawBlackHole draws o, a black hole, after everything it can lens: the
+// picture around it is bent by its gravity, then the accretion glow, the
+// horizon as a dark disc and the photon ring go on top.
+func (r *Renderer) drawBlackHole(o *physics.Object, cam *Camera, selected, glow bool) {
+	sx, sy := cam.WorldToScreen(o.X, o.Y)
+	horizon := math.Max(2, o.Radius*cam.zoom)
+
+	r.lens(sx, sy, horizon)
+	if glow {
+		r.drawAccretionGlow(sx, sy, horizon, o)
+	}
+	r.drawFilledCircle(sx, sy, int(horizon), [3]byte{})
+	r.drawPhotonRing(sx, sy, horizon)
+
+	sr := int(photonRing * horizon)
+	if selected {
+		r.drawCircleOutline(sx, sy, sr+3, [3]byte{255, 255, 0})
+	}
+	if o.Pinned {
+		r.drawCircleOutline(sx, sy, sr+2, [3]byte{255, 100, 100})
+	}
+}
+
+// lens bends the light of everything already drawn around a black hole at
+// (cx, cy). A pixel at distance θ from the centre shows what lies at the
+// point-lens source position β = θ − θE²/θ along the same line, so whatever
+// is right behind the hole is smeared into an Einstein ring and the far side
+// shows up as a second image. The bending fades out beyond the Einstein
+// radius so the lensed region blends into the rest of the picture.
+func (r *Renderer) lens(cx, cy, horizon float64) {
+	einstein := lensEinstein * horizon
+	reach := math.Min(lensReach*einstein, screenHeight)
+	minX := clampInt(int(cx-reach), 0, screenWidth)
+	maxX := clampInt(int(cx+reach)+1, 0, screenWidth)
+	minY := clampInt(int(cy-reach), 0, screenHeight)
+	maxY := clampInt(int(cy+reach)+1, 0, screenHeight)
+	w, h := maxX-minX, maxY-minY
+	if w <= 0 || h <= 0 {
+		return
+	}
+
+	// Read sources from a copy so bent pixels are not lensed twice
+	need := w * h * 4
+	if cap(r.lensBuf) < need {
+		r.lensBuf = make([]byte, need)
+	}
+	src := r.lensBuf[:need]
+	for j := 0; j < h; j++ {
+		row := ((minY+j)*screenWidth + minX) * 4
+		copy(src[j*w*4:(j+1)*w*4], r.pixels[row:row+w*4])
+	}
+
+	for j := minY; j < maxY; j++ {
+		for i := minX; i < maxX; i++ {
+			dx := float64(i) - cx
+			dy := float64(j) - cy
+			theta := math.Sqrt(dx*dx + dy*dy)
+			if theta >= reach || theta < horizon {
+				continue
+			}
+			bend := 1.0
+			if theta > einstein {
+				t := (theta - einstein) / (reach - einstein)
+				bend = 1 - t*t*(3-2*t)
+			}
+			scale := 1 - bend*einstein*einstein/(theta*theta)
+			si := int(math.Floor(cx+dx*scale)) - minX
+			sj := int(math.Floor(cy+dy*scale)) - minY
+			idx := (j*screenWidth + i) * 4
+			if si < 0 || si >= w || sj < 0 || sj >= h {
+				r.pixels[idx], r.pixels[idx+1], r.pixels[idx+2] = 0, 0, 0
+				continue
+			}
+			s := (sj*w + si) * 4
+			r.pixels[idx], r.pixels[idx+1], r.pixels[idx+2] = src[s], src[s+1], src[s+2]
+			r.pixels[idx+3] = 0xFF
+		}
+	}
+}
+
+// drawAccretionGlow adds the glow of matter falling into o: a disc from the
+// horizon out to accretionReach horizon radii, brightest at the horizon and
+// with a hot spot that turns with the hole's spin. It brightens with
+// o.Accretion, so it flares when the hole feeds and fades as it goes quiet.
+func (r *Renderer) drawAccretionGlow(cx, cy, horizon float64, o *physics.Object) {
+	t := math.Min(1, math.Sqrt(o.Accretion/accretionScale))
+	if t < 0.01 {
+		return
+	}
+	outer := accretionReach * horizon
+	r.addRing(cx, cy, horizon, outer, func(d, angle float64) ([3]float64, float64) {
+		k := t * (outer - d) / (outer - horizon)
+		return accretionColor, k * (1 + 0.5*math.Cos(angle-o.Angle))
+	})
+}
+
+// drawPhotonRing adds the thin ring of light orbiting just outside the
+// horizon.
+func (r *Renderer) drawPhotonRing(cx, cy, horizon float64) {
+	ring := photonRing * horizon
+	r.addRing(cx, cy, ring-2, ring+2, func(d, _ float64) ([3]float64, float64) {
+		x := (d - ring) / 0.8
+		return photonRingColor, 0.8 * math.Exp(-x*x)
+	})
+}
+
+// addRing adds light to the pixels between radii inner and outer around
+// (cx, cy). shade returns the colour and its weight for a pixel at distance
+// d and screen angle angle from the centre.
+func (r *Renderer) addRing(cx, cy, inner, outer float64, shade func(d, angle float64) ([3]float64, float64)) {
+	minX := clampInt(int(cx-outer), 0, screenWidth)
+	maxX := clampInt(int(cx+outer)+1, 0, screenWidth)
+	minY := clampInt(int(cy-outer), 0, screenHeight)
+	maxY := clampInt(int(cy+outer)+1, 0, screenHeight)
+	for j := minY; j < maxY; j++ {
+		for i := minX; i < maxX; i++ {
+			dx := float64(i) - cx
+			dy := float64(j) - cy
+			d := math.Sqrt(dx*dx + dy*dy)
+			if d < inner || d >= outer {
+				continue
+			}
+			color, k := shade(d, math.Atan2(dy, dx))
+			idx := (j*screenWidth + i) * 4
+			for c := 0; c < 3; c++ {
+				r.pixels[idx+c] = byte(math.Min(255, float64(r.pixels[idx+c])+color[c]*k))
+			}
+			r.pixels[idx+3] = 0xFF
+		}
+	}
+}
@@ -28,8 +28,8 @@ func (c *Camera) ScreenToWorld(sx, sy float64) (float64, float64) {
 }
 
 // WorldRadius converts a world-space radius to screen pixels.
-func (c *Camera) WorldRadius(r int) int {
-	sr := float64(r) * c.zoom
+func (c *Camera) WorldRadius(r float64) int {
+	sr := r * c.zoom
 	if sr < 1 {
 		return 1
 	}
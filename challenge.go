@@ -1,6 +1,10 @@
 package main
 
-import "math"
+import (
+	"math"
+
+	"github.com/bondar-pavel/gravity/physics"
+)
 
 type ChallengeState int
 
@@ -13,13 +17,14 @@ const (
 
 type LevelObject struct {
 	X, Y   float64
-	Radius int
+	Radius float64
 	Pinned bool
 }
 
 type Level struct {
 	Name            string
 	Objects         []LevelObject
+	Fixtures        []LevelFixture
 	OrbitZoneRadius float64
 }
 
@@ -30,7 +35,7 @@ type Challenge struct {
 	levels       []Level
 
 	// Orbit tracking
-	orbiter     *Object
+	orbiterID   physics.ObjectID
 	orbitCenter [2]float64 // center point for orbit zone (centroid of planets)
 	prevAngle   float64
 	totalAngle  float64
@@ -45,8 +50,13 @@ type Challenge struct {
 	resultTimer int
 
 	// Saved sandbox state
-	savedObjects []*Object
-	savedMerge   bool
+	savedObjects     []*physics.Object
+	savedConstraints []physics.Constraint
+	savedMerge       bool
+	savedCollide     bool
+	savedGravity     physics.GravityMode
+
+	unsubscribe func() // detaches handleEvent from the world
 }
 
 func newChallenge() *Challenge {
@@ -83,6 +93,27 @@ func newChallenge() *Challenge {
 			},
 			OrbitZoneRadius: 500,
 		},
+		{
+			Name: "Thin Air",
+			Objects: []LevelObject{
+				{800, 600, 40, true},
+			},
+			Fixtures: []LevelFixture{
+				{Kind: physics.KindDragZone, X: 800, Y: 600, Radius: 150, Drag: 0.01},
+			},
+			OrbitZoneRadius: 450,
+		},
+		{
+			Name: "Keep Out",
+			Objects: []LevelObject{
+				{800, 600, 40, true},
+			},
+			Fixtures: []LevelFixture{
+				{Kind: physics.KindRepulsor, X: 800, Y: 350, Radius: 20},
+				{Kind: physics.KindRepulsor, X: 800, Y: 850, Radius: 20},
+			},
+			OrbitZoneRadius: 500,
+		},
 	}
 
 	return &Challenge{
@@ -91,54 +122,67 @@ func newChallenge() *Challenge {
 	}
 }
 
-func (c *Challenge) Enter(world *World) {
+func (c *Challenge) Enter(world *physics.World) {
 	// Save sandbox state
-	c.savedObjects = make([]*Object, len(world.objects))
-	copy(c.savedObjects, world.objects)
-	c.savedMerge = world.mergeOnCollision
+	c.savedObjects = make([]*physics.Object, len(world.Objects()))
+	copy(c.savedObjects, world.Objects())
+	c.savedConstraints = append([]physics.Constraint(nil), world.Constraints()...)
+	c.savedMerge = world.MergeOnCollision
+	c.savedCollide = world.BounceOnParticleCollision
+	c.savedGravity = world.GravityMode
 
 	c.active = true
 	c.state = ChallengeAiming
-	c.orbiter = nil
-	world.mergeOnCollision = true
+	c.orbiterID = physics.NoObject
+	world.MergeOnCollision = true
+	world.BounceOnParticleCollision = true    // crashes are detected by collision events
+	world.GravityMode = physics.GravityLegacy // levels are tuned for arcade gravity
+	c.unsubscribe = world.Subscribe(func(e physics.Event) { c.handleEvent(world, e) })
 	c.loadLevel(world)
 }
 
-func (c *Challenge) Exit(world *World) {
+func (c *Challenge) Exit(world *physics.World) {
 	c.active = false
-	c.orbiter = nil
+	c.orbiterID = physics.NoObject
+	c.unsubscribe()
+	c.unsubscribe = nil
 
 	// Restore sandbox
-	world.objects = c.savedObjects
-	world.mergeOnCollision = c.savedMerge
+	world.ReplaceObjects(c.savedObjects)
+	world.SetConstraints(c.savedConstraints)
+	world.MergeOnCollision = c.savedMerge
+	world.BounceOnParticleCollision = c.savedCollide
+	world.GravityMode = c.savedGravity
 	c.savedObjects = nil
+	c.savedConstraints = nil
 }
 
-func (c *Challenge) loadLevel(world *World) {
+func (c *Challenge) loadLevel(world *physics.World) {
 	level := c.levels[c.currentLevel]
-	world.objects = world.objects[:0]
+	world.Clear()
 
 	var cx, cy float64
 	for _, lo := range level.Objects {
 		obj := world.AddObject(lo.X, lo.Y, lo.Radius)
-		obj.pinned = lo.Pinned
+		obj.Pinned = lo.Pinned
 		cx += lo.X
 		cy += lo.Y
 	}
+	addLevelFixtures(world, level.Fixtures)
 
 	// Centroid for orbit zone center
 	n := float64(len(level.Objects))
 	c.orbitCenter = [2]float64{cx / n, cy / n}
 	c.orbitZoneRadius = level.OrbitZoneRadius
 
-	c.orbiter = nil
+	c.orbiterID = physics.NoObject
 	c.state = ChallengeAiming
 	c.totalAngle = 0
 	c.orbitCount = 0
 	c.resultTimer = 0
 }
 
-func (c *Challenge) ChangeLevel(delta int, world *World) {
+func (c *Challenge) ChangeLevel(delta int, world *physics.World) {
 	if c.state != ChallengeAiming {
 		return
 	}
@@ -152,30 +196,30 @@ func (c *Challenge) ChangeLevel(delta int, world *World) {
 	c.loadLevel(world)
 }
 
-func (c *Challenge) LaunchOrbiter(world *World, x, y, vx, vy float64) {
+func (c *Challenge) LaunchOrbiter(world *physics.World, x, y, vx, vy float64) {
 	if c.state != ChallengeAiming {
 		return
 	}
 
 	obj := world.AddObject(x, y, 5)
-	obj.velocityX = vx
-	obj.velocityY = vy
-	obj.color = [3]byte{255, 255, 100} // bright yellow
+	obj.VelocityX = vx
+	obj.VelocityY = vy
+	obj.Color = [3]byte{255, 255, 100} // bright yellow
 
-	c.orbiter = obj
+	c.orbiterID = obj.ID
 	c.state = ChallengeOrbiting
 	c.totalAngle = 0
 	c.orbitCount = 0
 	c.newBest = false
 
 	// Initialize angle tracking from orbit center
-	dx := obj.x - c.orbitCenter[0]
-	dy := obj.y - c.orbitCenter[1]
+	dx := obj.X - c.orbitCenter[0]
+	dy := obj.Y - c.orbitCenter[1]
 	c.prevAngle = math.Atan2(dy, dx)
 }
 
 // Update is called each physics tick while challenge is active.
-func (c *Challenge) Update(world *World) {
+func (c *Challenge) Update(world *physics.World) {
 	if !c.active {
 		return
 	}
@@ -188,29 +232,16 @@ func (c *Challenge) Update(world *World) {
 	}
 }
 
-func (c *Challenge) trackOrbit(world *World) {
-	if c.orbiter == nil {
+func (c *Challenge) trackOrbit(world *physics.World) {
+	orbiter := world.Object(c.orbiterID)
+	if orbiter == nil {
 		c.state = ChallengeAiming
 		return
 	}
 
-	// Check crash: distance to any planet < sum of radii
-	for _, o := range world.objects {
-		if o == c.orbiter || !o.pinned {
-			continue
-		}
-		dx := c.orbiter.x - o.x
-		dy := c.orbiter.y - o.y
-		dist := math.Sqrt(dx*dx + dy*dy)
-		if dist < float64(c.orbiter.radius+o.radius) {
-			c.endRound(ChallengeCrashed, world)
-			return
-		}
-	}
-
 	// Check escape: distance from orbit center > zone radius
-	dx := c.orbiter.x - c.orbitCenter[0]
-	dy := c.orbiter.y - c.orbitCenter[1]
+	dx := orbiter.X - c.orbitCenter[0]
+	dy := orbiter.Y - c.orbitCenter[1]
 	dist := math.Sqrt(dx*dx + dy*dy)
 	if dist > c.orbitZoneRadius {
 		c.endRound(ChallengeEscaped, world)
@@ -230,7 +261,29 @@ func (c *Challenge) trackOrbit(world *World) {
 	c.prevAngle = currentAngle
 }
 
-func (c *Challenge) endRound(state ChallengeState, world *World) {
+// handleEvent ends the round when the orbiter touches a planet or leaves the
+// world. Levels hold only pinned planets besides the orbiter, so any contact
+// is a crash.
+func (c *Challenge) handleEvent(world *physics.World, e physics.Event) {
+	if c.state != ChallengeOrbiting || c.orbiterID == physics.NoObject {
+		return
+	}
+	id := c.orbiterID
+	switch e.Kind {
+	case physics.EventCollision, physics.EventMerge, physics.EventFragment, physics.EventDisrupted,
+		physics.EventAbsorbed:
+		if e.A == id || e.B == id {
+			c.endRound(ChallengeCrashed, world)
+		}
+	case physics.EventRemoved:
+		if e.A == id {
+			c.orbiterID = physics.NoObject
+			c.endRound(ChallengeEscaped, world)
+		}
+	}
+}
+
+func (c *Challenge) endRound(state ChallengeState, world *physics.World) {
 	c.state = state
 	c.resultTimer = 0
 
@@ -240,13 +293,11 @@ func (c *Challenge) endRound(state ChallengeState, world *World) {
 	}
 
 	// Remove orbiter
-	if c.orbiter != nil {
-		world.RemoveObject(c.orbiter)
-		c.orbiter = nil
-	}
+	world.RemoveID(c.orbiterID)
+	c.orbiterID = physics.NoObject
 }
 
-func (c *Challenge) RetryLevel(world *World) {
+func (c *Challenge) RetryLevel(world *physics.World) {
 	if c.state != ChallengeCrashed && c.state != ChallengeEscaped {
 		return
 	}
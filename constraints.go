@@ -0,0 +1,83 @@
+package main
+
+import (
+	"math"
+
+	"github.com/bondar-pavel/gravity/physics"
+)
+
+// constraintColors are the colors of links by kind; springs shade towards
+// red as they stretch.
+var constraintColors = [physics.NumConstraintKinds][3]byte{
+	physics.ConstraintSpring: {120, 220, 120},
+	physics.ConstraintRod:    {200, 200, 210},
+	physics.ConstraintRope:   {200, 170, 110},
+}
+
+// drawConstraints draws every link between objects: a spring as a zigzag,
+// a rod as a straight line and a rope as a line that turns dashed when slack.
+func (r *Renderer) drawConstraints(world *physics.World, cam *Camera) {
+	for _, c := range world.Constraints() {
+		a, b := world.Object(c.A), world.Object(c.B)
+		if a == nil || b == nil {
+			continue
+		}
+		ax, ay := cam.WorldToScreen(a.X, a.Y)
+		bx, by := cam.WorldToScreen(b.X, b.Y)
+		d := math.Hypot(b.X-a.X, b.Y-a.Y)
+		color := constraintColors[c.Kind]
+
+		switch c.Kind {
+		case physics.ConstraintSpring:
+			if c.Length > 0 {
+				t := math.Min(1, math.Abs(d-c.Length)/c.Length)
+				color[0] = byte(float64(color[0]) + (255-float64(color[0]))*t)
+				color[1] = byte(float64(color[1]) * (1 - 0.6*t))
+			}
+			r.drawZigzag(ax, ay, bx, by, 12, 4*cam.zoom, color)
+		case physics.ConstraintRope:
+			if d < c.Length-0.5 {
+				r.drawDashedLine(ax, ay, bx, by, color)
+			} else {
+				r.drawLine(ax, ay, bx, by, color)
+			}
+		default:
+			r.drawLine(ax, ay, bx, by, color)
+		}
+	}
+}
+
+// drawZigzag draws a zigzag of n teeth of half-width amp across the line
+// from (x0, y0) to (x1, y1).
+func (r *Renderer) drawZigzag(x0, y0, x1, y1 float64, n int, amp float64, color [3]byte) {
+	dx, dy := x1-x0, y1-y0
+	l := math.Hypot(dx, dy)
+	if l == 0 {
+		return
+	}
+	px, py := -dy/l*amp, dx/l*amp
+	prevX, prevY := x0, y0
+	for k := 1; k <= n; k++ {
+		t := float64(k) / float64(n)
+		x, y := x0+dx*t, y0+dy*t
+		if k < n {
+			side := float64(1 - 2*(k%2))
+			x += px * side
+			y += py * side
+		}
+		r.drawLine(prevX, prevY, x, y, color)
+		prevX, prevY = x, y
+	}
+}
+
+// drawLinkPreview draws the link being dragged from an object to the cursor.
+func (r *Renderer) drawLinkPreview(input *InputState, world *physics.World, cam *Camera) {
+	from := world.Object(input.linkFrom)
+	if from == nil {
+		return
+	}
+	fx, fy := cam.WorldToScreen(from.X, from.Y)
+	wx, wy := input.cursorWorld(cam)
+	cx, cy := cam.WorldToScreen(wx, wy)
+	r.drawDashedLine(fx, fy, cx, cy, constraintColors[input.linkKind])
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"math"
+
+	"github.com/bondar-pavel/gravity/physics"
+	"github.com/bondar-pavel/gravity/sandbox"
+)
+
+// Fixture placement with the brush; [Q] cycles between bodies and fixtures.
+const (
+	zoneScale        = 5      // zone radius, in brush radii
+	zoneFieldScale   = 0.0002 // gravity zone acceleration per pixel of drag
+	defaultZoneField = 0.02   // gravity zone acceleration, pointing down, for a plain click
+	defaultZoneDrag  = 0.02   // drag zone speed loss per tick
+)
+
+// LevelFixture is a fixture in challenge or target practice level data.
+// FieldX/FieldY apply to gravity zones and Drag to drag zones; a wormhole is
+// linked to the mouth at index Link in the level's Fixtures.
+type LevelFixture struct {
+	Kind           physics.ObjectKind
+	X, Y           float64
+	Radius         float64
+	FieldX, FieldY float64
+	Drag           float64
+	Link           int
+}
+
+// addLevelFixtures places a level's fixtures and links its wormholes.
+func addLevelFixtures(world *physics.World, fixtures []LevelFixture) {
+	objs := make([]*physics.Object, len(fixtures))
+	for i, f := range fixtures {
+		obj := world.AddFixture(f.Kind, f.X, f.Y, f.Radius)
+		obj.FieldX, obj.FieldY = f.FieldX, f.FieldY
+		obj.Drag = f.Drag
+		obj.Color = sandbox.FixtureColors[f.Kind]
+		objs[i] = obj
+	}
+	for i, f := range fixtures {
+		if f.Kind == physics.KindWormhole && f.Link != i && f.Link >= 0 && f.Link < len(objs) {
+			physics.LinkWormholes(objs[i], objs[f.Link])
+		}
+	}
+}
+
+// fixtureRadius returns the radius of a fixture of kind placed with brush
+// radius r. Zones are much larger than the bodies they act on.
+func fixtureRadius(kind physics.ObjectKind, r float64) float64 {
+	if kind == physics.KindGravityZone || kind == physics.KindDragZone {
+		return zoneScale * r
+	}
+	return r
+}
+
+// brushName describes what the brush places.
+func (s *InputState) brushName() string {
+	if s.nextKind != physics.KindBody {
+		return s.nextKind.String()
+	}
+	return s.nextMaterial.String()
+}
+
+// placeAction returns the action that places the brush's fixture where the
+// aim started. For a gravity zone the drag to (wx, wy) sets the field; a
+// wormhole mouth is linked to the previous one if that is still unpaired.
+func (s *InputState) placeAction(wx, wy float64) sandbox.Action {
+	a := sandbox.Action{
+		Kind:    sandbox.ActionPlace,
+		X:       s.aimStartX,
+		Y:       s.aimStartY,
+		Radius:  fixtureRadius(s.nextKind, float64(s.nextRadius)),
+		Fixture: s.nextKind.String(),
+	}
+	switch s.nextKind {
+	case physics.KindGravityZone:
+		dx, dy := wx-s.aimStartX, wy-s.aimStartY
+		if math.Hypot(dx, dy) < 5 {
+			a.VY = defaultZoneField
+		} else {
+			a.VX, a.VY = dx*zoneFieldScale, dy*zoneFieldScale
+		}
+	case physics.KindDragZone:
+		a.Value = defaultZoneDrag
+	case physics.KindWormhole:
+		a.ID = s.openMouth
+	}
+	return a
+}
+
+// placed notes the wormhole mouth a placement left unpaired, so the next
+// one placed links to it.
+func (s *InputState) placed(world *physics.World, a sandbox.Action) {
+	objs := world.Objects()
+	if a.Fixture != physics.KindWormhole.String() || len(objs) == 0 {
+		return
+	}
+	if obj := objs[len(objs)-1]; obj.Link == physics.NoObject {
+		s.openMouth = obj.ID
+	} else {
+		s.openMouth = physics.NoObject
+	}
+}
+
+// drawFixtures draws every fixture, beneath the bodies.
+func (r *Renderer) drawFixtures(world *physics.World, cam *Camera, selectedID physics.ObjectID) {
+	for _, o := range world.Objects() {
+		if o.Kind != physics.KindBody {
+			r.drawFixture(o, world, cam, o.ID == selectedID)
+		}
+	}
+}
+
+// drawFixture draws a zone as a tinted disc, a wormhole mouth as nested rings
+// with a dashed line to its partner, and a repulsor as a disc in rings.
+func (r *Renderer) drawFixture(o *physics.Object, world *physics.World, cam *Camera, selected bool) {
+	sx, sy := cam.WorldToScreen(o.X, o.Y)
+	radius := o.Radius * cam.zoom
+	sr := cam.WorldRadius(o.Radius)
+	c := [3]float64{float64(o.Color[0]), float64(o.Color[1]), float64(o.Color[2])}
+
+	switch o.Kind {
+	case physics.KindGravityZone:
+		r.addRing(sx, sy, 0, radius, func(float64, float64) ([3]float64, float64) {
+			return c, 0.12
+		})
+		r.drawCircleOutline(sx, sy, sr, o.Color)
+		// Arrow along the field, its length growing with the strength
+		if g := math.Hypot(o.FieldX, o.FieldY); g > 0 {
+			l := math.Min(radius*0.8, math.Max(10, g/zoneFieldScale*cam.zoom))
+			ux, uy := o.FieldX/g, o.FieldY/g
+			tx, ty := sx+ux*l/2, sy+uy*l/2
+			r.drawLine(sx-ux*l/2, sy-uy*l/2, tx, ty, o.Color)
+			r.drawLine(tx, ty, tx-(ux+uy)*6, ty-(uy-ux)*6, o.Color)
+			r.drawLine(tx, ty, tx-(ux-uy)*6, ty-(uy+ux)*6, o.Color)
+		}
+	case physics.KindDragZone:
+		// Denser towards the middle, like an atmosphere
+		r.addRing(sx, sy, 0, radius, func(d, _ float64) ([3]float64, float64) {
+			return c, 0.3 * (1 - d/radius)
+		})
+	case physics.KindWormhole:
+		if exit := world.Object(o.Link); exit != nil && o.ID < exit.ID {
+			ex, ey := cam.WorldToScreen(exit.X, exit.Y)
+			r.drawDashedLine(sx, sy, ex, ey, [3]byte{o.Color[0] / 3, o.Color[1] / 3, o.Color[2] / 3})
+		}
+		r.drawFilledCircle(sx, sy, sr, [3]byte{10, 0, 25})
+		for k := 0; k < 3; k++ {
+			f := 1 - 0.3*float64(k)
+			col := [3]byte{byte(c[0] * f), byte(c[1] * f), byte(c[2] * f)}
+			r.drawCircleOutline(sx, sy, sr-3*k, col)
+		}
+	case physics.KindRepulsor:
+		r.drawCircleOutline(sx, sy, sr+4, [3]byte{o.Color[0] / 2, o.Color[1] / 2, o.Color[2] / 2})
+		r.drawFilledCircle(sx, sy, sr, o.Color)
+	}
+
+	if selected {
+		r.drawCircleOutline(sx, sy, sr+3, [3]byte{255, 255, 0})
+	}
+}
+
+// drawPlacement previews the fixture the brush is about to place, with the
+// field a gravity zone would get.
+func (r *Renderer) drawPlacement(input *InputState, cam *Camera) {
+	sx, sy := cam.WorldToScreen(input.aimStartX, input.aimStartY)
+	sr := cam.WorldRadius(fixtureRadius(input.nextKind, float64(input.nextRadius)))
+	r.drawCircleOutline(sx, sy, sr, sandbox.FixtureColors[input.nextKind])
+	if input.nextKind == physics.KindGravityZone {
+		cx, cy := input.cursorWorld(cam)
+		ex, ey := cam.WorldToScreen(cx, cy)
+		r.drawLine(sx, sy, ex, ey, sandbox.FixtureColors[input.nextKind])
+	}
+}
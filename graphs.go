@@ -0,0 +1,143 @@
+package main
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+
+	"github.com/bondar-pavel/gravity/physics"
+)
+
+const graphHistoryLen = 1200 // ticks plotted (~10 s at 1x speed, 2 ticks per frame)
+
+// graphSample is one tick of the plotted series. Values that are unavailable
+// (no selection, no pinned body, diagnostics off) are NaN and leave a gap.
+type graphSample struct {
+	energy        float64
+	count         float64
+	speed         float64 // of the watched object
+	nearestPinned float64 // distance from the watched object to the nearest pinned body
+}
+
+// newGraphSample measures the current tick. watched is the object whose speed
+// and distance are plotted, or nil.
+func newGraphSample(world *physics.World, watched *physics.Object) graphSample {
+	s := graphSample{
+		energy:        math.NaN(),
+		count:         float64(len(world.Objects())),
+		speed:         math.NaN(),
+		nearestPinned: math.NaN(),
+	}
+	if world.TrackDiagnostics {
+		s.energy = world.Diagnostics().Energy()
+	}
+	if watched == nil {
+		return s
+	}
+	o := watched
+	s.speed = math.Sqrt(o.VelocityX*o.VelocityX + o.VelocityY*o.VelocityY)
+	for _, p := range world.Objects() {
+		if !p.Pinned || p == o {
+			continue
+		}
+		d := math.Sqrt((p.X-o.X)*(p.X-o.X) + (p.Y-o.Y)*(p.Y-o.Y))
+		if math.IsNaN(s.nearestPinned) || d < s.nearestPinned {
+			s.nearestPinned = d
+		}
+	}
+	return s
+}
+
+// Graph panel layout in screen pixels (right-hand column).
+const (
+	graphWidth   = 400
+	graphHeight  = 80
+	graphSpacing = 40 // room for the label above each plot
+	graphLeft    = screenWidth - graphWidth - 16
+	graphTop     = 160
+)
+
+type graphSeries struct {
+	label string
+	value func(graphSample) float64
+	color [3]byte
+}
+
+var graphSeriesList = []graphSeries{
+	{"Total energy", func(s graphSample) float64 { return s.energy }, [3]byte{255, 200, 80}},
+	{"Particles", func(s graphSample) float64 { return s.count }, [3]byte{120, 200, 255}},
+	{"Selected speed", func(s graphSample) float64 { return s.speed }, [3]byte{130, 255, 130}},
+	{"Nearest pinned", func(s graphSample) float64 { return s.nearestPinned }, [3]byte{255, 130, 200}},
+}
+
+// seriesRange returns the min and max of a series, ignoring gaps.
+func seriesRange(h *physics.Ring[graphSample], value func(graphSample) float64) (float64, float64, bool) {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for i := 0; i < h.Len(); i++ {
+		v := value(h.At(i))
+		if math.IsNaN(v) {
+			continue
+		}
+		lo = math.Min(lo, v)
+		hi = math.Max(hi, v)
+	}
+	return lo, hi, lo <= hi
+}
+
+// drawGraphs plots every series as a polyline scaled to its own range.
+func (r *Renderer) drawGraphs(h *physics.Ring[graphSample]) {
+	for gi, series := range graphSeriesList {
+		x0 := float64(graphLeft)
+		y0 := float64(graphTop + gi*(graphHeight+graphSpacing))
+		x1 := x0 + graphWidth
+		y1 := y0 + graphHeight
+
+		frame := [3]byte{60, 60, 60}
+		r.drawLine(x0, y0, x1, y0, frame)
+		r.drawLine(x0, y1, x1, y1, frame)
+		r.drawLine(x0, y0, x0, y1, frame)
+		r.drawLine(x1, y0, x1, y1, frame)
+
+		lo, hi, ok := seriesRange(h, series.value)
+		if !ok {
+			continue
+		}
+		span := hi - lo
+		if span == 0 {
+			span = 1 // flat line through the middle
+			lo -= 0.5
+		}
+
+		prevX, prevY, havePrev := 0.0, 0.0, false
+		for i := 0; i < h.Len(); i++ {
+			v := series.value(h.At(i))
+			if math.IsNaN(v) {
+				havePrev = false
+				continue
+			}
+			px := x0 + graphWidth*float64(i)/float64(graphHistoryLen-1)
+			py := y1 - graphHeight*(v-lo)/span
+			if havePrev {
+				r.drawLine(prevX, prevY, px, py, series.color)
+			}
+			prevX, prevY, havePrev = px, py, true
+		}
+	}
+}
+
+// drawGraphLabels prints each graph's title and latest value on the HUD.
+func (r *Renderer) drawGraphLabels(h *physics.Ring[graphSample]) {
+	for gi, series := range graphSeriesList {
+		x := int(graphLeft / hudScale)
+		y := int(float64(graphTop+gi*(graphHeight+graphSpacing)-graphSpacing/2) / hudScale)
+
+		label := series.label
+		if h.Len() > 0 {
+			if v := series.value(h.At(h.Len() - 1)); !math.IsNaN(v) {
+				label += fmt.Sprintf(": %.4g", v)
+			}
+		}
+		ebitenutil.DebugPrintAt(r.hudImage, label, x, y-4)
+	}
+}
@@ -1,6 +1,13 @@
 package main
 
-import "github.com/hajimehoshi/ebiten/v2"
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"github.com/bondar-pavel/gravity/physics"
+	"github.com/bondar-pavel/gravity/sandbox"
+)
 
 type InputState struct {
 	// Slingshot aiming
@@ -10,13 +17,25 @@ type InputState struct {
 
 	// Object dragging
 	dragging bool
-	dragObj  *Object
+	dragID   physics.ObjectID
+
+	// Linking two objects with a modifier-drag
+	linking  bool
+	linkFrom physics.ObjectID
+	linkKind physics.ConstraintKind
 
 	// Selection
-	selectedObj *Object
+	selectedID physics.ObjectID
+
+	// Particle size and material, or the fixture to place instead
+	nextRadius   int
+	nextMaterial physics.Material
+	nextKind     physics.ObjectKind
+	openMouth    physics.ObjectID // wormhole mouth waiting for its partner
 
-	// Particle size
-	nextRadius int
+	// Rocket control
+	gamepads    []ebiten.GamepadID
+	padThrottle float64 // last gamepad trigger value
 
 	// Time
 	paused   bool
@@ -32,6 +51,24 @@ type InputState struct {
 	// Visualization
 	showField        bool
 	showTrajectories bool
+	showAccretion    bool // accretion glow around feeding black holes
+	showDiagnostics  bool
+	showGraphs       bool
+	showPresets      bool
+	graphHistory     *physics.Ring[graphSample] // recorded every tick while showGraphs is on
+
+	// Rewind timeline
+	timeline    *physics.Timeline
+	timelinePos int  // snapshot being viewed, -1 when live
+	scrubbing   bool // dragging on the timeline bar
+
+	// Session recording and playback
+	recording *sandbox.Recording // being recorded, or nil
+	replay    *sandbox.Playback  // being played back, or nil
+
+	// Transient HUD message (e.g. save/load result)
+	statusMsg   string
+	statusTimer int // frames left to show statusMsg
 
 	// Debounce tracking
 	prevKeys       map[ebiten.Key]bool
@@ -44,6 +81,12 @@ func newInputState() *InputState {
 		simSpeed:   1.0,
 		paused:     true,
 		prevKeys:   make(map[ebiten.Key]bool),
+
+		showAccretion: true,
+
+		graphHistory: physics.NewRing[graphSample](graphHistoryLen),
+		timeline:     physics.NewTimeline(timelineCapacity, timelineInterval),
+		timelinePos:  -1,
 	}
 }
 
@@ -55,7 +98,13 @@ func (s *InputState) justPressed(key ebiten.Key) bool {
 	return pressed && !was
 }
 
-func (s *InputState) Update(world *World, cam *Camera, challenge *Challenge, target *TargetPractice) {
+func (s *InputState) Update(world *physics.World, cam *Camera, challenge *Challenge, target *TargetPractice, mission *MissionMode) {
+	// Playback drives the world; only pause, camera and stop are live
+	if s.replay != nil {
+		s.handleReplayControls(cam)
+		return
+	}
+
 	// Challenge mode toggle
 	if s.justPressed(ebiten.KeyO) {
 		if challenge.active {
@@ -68,10 +117,14 @@ func (s *InputState) Update(world *World, cam *Camera, challenge *Challenge, tar
 			target.Exit(world)
 			s.aiming = false
 		}
+		if mission.active {
+			mission.Exit(world, cam)
+		}
+		s.endRecording(world)
 		challenge.Enter(world)
 		s.aiming = false
 		s.dragging = false
-		s.selectedObj = nil
+		s.selectedID = physics.NoObject
 		return
 	}
 
@@ -87,37 +140,75 @@ func (s *InputState) Update(world *World, cam *Camera, challenge *Challenge, tar
 			challenge.Exit(world)
 			s.aiming = false
 		}
+		if mission.active {
+			mission.Exit(world, cam)
+		}
+		s.endRecording(world)
 		target.Enter(world)
 		s.aiming = false
 		s.dragging = false
-		s.selectedObj = nil
+		s.selectedID = physics.NoObject
+		return
+	}
+
+	// Mission mode toggle
+	if s.justPressed(ebiten.KeyW) {
+		if mission.active {
+			mission.Exit(world, cam)
+			return
+		}
+		if challenge.active {
+			challenge.Exit(world)
+		}
+		if target.active {
+			target.Exit(world)
+		}
+		s.endRecording(world)
+		if err := mission.Enter(world, cam); err != nil {
+			s.showStatus("Missions unavailable: " + err.Error())
+			return
+		}
+		s.resetObjectRefs()
 		return
 	}
 
 	if challenge.active {
-		s.handleTimeControl()
+		s.handleTimeControl(world)
 		s.handleCamera(cam)
 		s.handleChallengeInput(world, cam, challenge)
 		return
 	}
 
 	if target.active {
-		s.handleTimeControl()
+		s.handleTimeControl(world)
 		s.handleCamera(cam)
 		s.handleTargetInput(world, cam, target)
 		return
 	}
 
+	if mission.active {
+		s.handleTimeControl(world)
+		s.handleCamera(cam)
+		s.handleMissionInput(world, cam, mission)
+		return
+	}
+
 	// Normal sandbox mode
-	s.handleTimeControl()
+	s.handleTimeControl(world)
 	s.handleSizeControl()
 	s.handleCamera(cam)
 	s.handleSelection(world, cam)
-	s.handleMouse(world, cam)
+	s.handleRocket(world)
+	if !s.handleTimeline(world) {
+		s.handleMouse(world, cam)
+	}
 	s.handleToggles(world)
+	s.handleSceneFiles(world, cam)
+	s.handlePresets(world, cam)
+	s.handleRecording(world, cam)
 }
 
-func (s *InputState) handleChallengeInput(world *World, cam *Camera, ch *Challenge) {
+func (s *InputState) handleChallengeInput(world *physics.World, cam *Camera, ch *Challenge) {
 	// Escape exits challenge
 	if s.justPressed(ebiten.KeyEscape) {
 		ch.Exit(world)
@@ -172,7 +263,7 @@ func (s *InputState) handleChallengeInput(world *World, cam *Camera, ch *Challen
 	}
 }
 
-func (s *InputState) handleTargetInput(world *World, cam *Camera, tp *TargetPractice) {
+func (s *InputState) handleTargetInput(world *physics.World, cam *Camera, tp *TargetPractice) {
 	// Escape exits target practice
 	if s.justPressed(ebiten.KeyEscape) {
 		tp.Exit(world)
@@ -228,40 +319,222 @@ func (s *InputState) handleTargetInput(world *World, cam *Camera, tp *TargetPrac
 	}
 }
 
-func (s *InputState) handleToggles(world *World) {
-	if s.justPressed(ebiten.KeyG) {
-		s.showField = !s.showField
+func (s *InputState) handleMissionInput(world *physics.World, cam *Camera, m *MissionMode) {
+	// Escape exits missions
+	if s.justPressed(ebiten.KeyEscape) {
+		m.Exit(world, cam)
+		return
+	}
+
+	// Mission cycling; the arrow keys fly the craft
+	if s.justPressed(ebiten.KeyPageUp) {
+		m.ChangeMission(-1, world, cam)
 	}
-	if s.justPressed(ebiten.KeyV) {
-		s.showTrajectories = !s.showTrajectories
+	if s.justPressed(ebiten.KeyPageDown) {
+		m.ChangeMission(1, world, cam)
 	}
-	if s.justPressed(ebiten.KeyM) {
-		world.mergeOnCollision = !world.mergeOnCollision
+
+	// After success or failure: click to retry
+	if m.state != MissionFlying {
+		if ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
+			m.RetryMission(world, cam)
+		}
+		return
 	}
-	if s.justPressed(ebiten.KeyF) {
-		world.frictionEnabled = !world.frictionEnabled
+
+	if craft := m.Craft(world); craft != nil {
+		s.steer(world, craft)
 	}
 }
 
-func (s *InputState) handleTimeControl() {
+// toggleKeys maps single-key toggles to the actions they perform.
+var toggleKeys = []struct {
+	key  ebiten.Key
+	kind sandbox.ActionKind
+}{
+	{ebiten.KeyG, sandbox.ActionToggleField},
+	{ebiten.KeyV, sandbox.ActionToggleTrajectories},
+	{ebiten.KeyJ, sandbox.ActionToggleAccretion},
+	{ebiten.KeyD, sandbox.ActionToggleDiagnostics},
+	{ebiten.KeyH, sandbox.ActionToggleGraphs},
+	{ebiten.KeyZ, sandbox.ActionToggleCollide},
+	{ebiten.KeyM, sandbox.ActionToggleMerge},
+	{ebiten.KeyX, sandbox.ActionToggleFragment},
+	{ebiten.KeyE, sandbox.ActionToggleEjecta},
+	{ebiten.KeyK, sandbox.ActionToggleTidal},
+	{ebiten.KeyF, sandbox.ActionToggleFriction},
+	{ebiten.KeyN, sandbox.ActionToggleGravity},
+	{ebiten.KeyI, sandbox.ActionCycleIntegrator},
+	{ebiten.KeyA, sandbox.ActionToggleAdaptive},
+	{ebiten.KeyB, sandbox.ActionToggleSolver},
+}
+
+func (s *InputState) handleToggles(world *physics.World) {
+	for _, t := range toggleKeys {
+		if s.justPressed(t.key) {
+			s.perform(world, sandbox.Action{Kind: t.kind})
+		}
+	}
+	if s.justPressed(ebiten.KeyR) && s.showDiagnostics {
+		s.perform(world, sandbox.Action{Kind: sandbox.ActionResetDrift})
+	}
+	if s.justPressed(ebiten.KeyPeriod) {
+		s.perform(world, sandbox.Action{Kind: sandbox.ActionTheta, Value: 0.1})
+	}
+	if s.justPressed(ebiten.KeyComma) {
+		s.perform(world, sandbox.Action{Kind: sandbox.ActionTheta, Value: -0.1})
+	}
+}
+
+// presetKeys select built-in scenes by number.
+var presetKeys = []ebiten.Key{
+	ebiten.Key1, ebiten.Key2, ebiten.Key3, ebiten.Key4, ebiten.Key5,
+	ebiten.Key6, ebiten.Key7, ebiten.Key8, ebiten.Key9,
+}
+
+// handlePresets toggles the preset menu (L) and loads presets by number.
+func (s *InputState) handlePresets(world *physics.World, cam *Camera) {
+	if s.justPressed(ebiten.KeyL) {
+		s.showPresets = !s.showPresets
+	}
+	for i, key := range presetKeys {
+		if !s.justPressed(key) || i >= len(sandbox.Presets) {
+			continue
+		}
+		s.endRecording(world)
+		applyPreset(sandbox.Presets[i], world, cam)
+		s.resetObjectRefs()
+		s.resetTimeline()
+		s.showPresets = false
+		s.showStatus("Loaded preset: " + sandbox.Presets[i].Name)
+	}
+}
+
+// handleSceneFiles saves (F5) and loads (F9) the quick-save scene.
+func (s *InputState) handleSceneFiles(world *physics.World, cam *Camera) {
+	if s.statusTimer > 0 {
+		s.statusTimer--
+	}
+	if s.justPressed(ebiten.KeyF5) {
+		if err := SaveScene(quickSavePath, world, cam); err != nil {
+			s.showStatus("Save failed: " + err.Error())
+		} else {
+			s.showStatus("Saved " + quickSavePath)
+		}
+	}
+	if s.justPressed(ebiten.KeyF9) {
+		s.endRecording(world)
+		if err := LoadScene(quickSavePath, world, cam); err != nil {
+			s.showStatus("Load failed: " + err.Error())
+		} else {
+			s.resetObjectRefs()
+			s.resetTimeline()
+			s.showStatus("Loaded " + quickSavePath)
+		}
+	}
+}
+
+// handleRecording starts and stops session recording (F6) and plays back
+// the last recording (F10).
+func (s *InputState) handleRecording(world *physics.World, cam *Camera) {
+	if s.justPressed(ebiten.KeyF6) {
+		if s.recording != nil {
+			s.stopRecording(world)
+		} else {
+			s.startRecording(world, cam)
+		}
+	}
+	if s.justPressed(ebiten.KeyF10) {
+		s.endRecording(world)
+		rec, err := sandbox.LoadRecording(recordingPath)
+		if err == nil {
+			err = s.startReplay(rec, world, cam)
+		}
+		if err != nil {
+			s.showStatus("Replay failed: " + err.Error())
+		} else {
+			s.showStatus("Replaying " + recordingPath + "... [F10] to stop")
+		}
+	}
+}
+
+// handleReplayControls is the only input handled during playback.
+func (s *InputState) handleReplayControls(cam *Camera) {
+	if s.statusTimer > 0 {
+		s.statusTimer--
+	}
+	if s.justPressed(ebiten.KeyP) {
+		s.paused = !s.paused
+	}
+	s.handleCamera(cam)
+	if s.justPressed(ebiten.KeyF10) {
+		s.replay = nil
+		s.paused = true
+		s.showStatus("Replay stopped")
+	}
+}
+
+// endRecording saves the recording in progress, if any, before the scene
+// is replaced by something the recording cannot reproduce.
+func (s *InputState) endRecording(world *physics.World) {
+	if s.recording != nil {
+		s.stopRecording(world)
+	}
+}
+
+// showStatus displays a message in the HUD for a few seconds.
+func (s *InputState) showStatus(msg string) {
+	s.statusMsg = msg
+	s.statusTimer = 180
+}
+
+// resetObjectRefs drops references to objects that are no longer in the world.
+func (s *InputState) resetObjectRefs() {
+	s.selectedID = physics.NoObject
+	s.dragID = physics.NoObject
+	s.dragging = false
+	s.aiming = false
+	s.linking = false
+	s.openMouth = physics.NoObject
+}
+
+// updateTracking enables diagnostics sampling while any view needs it and
+// restarts the drift baseline when it is switched on.
+func (s *InputState) updateTracking(world *physics.World) {
+	track := s.showDiagnostics || s.showGraphs
+	if track && !world.TrackDiagnostics {
+		world.ResetDiagnostics()
+	}
+	world.TrackDiagnostics = track
+}
+
+func (s *InputState) handleTimeControl(world *physics.World) {
 	if s.justPressed(ebiten.KeyP) {
 		s.paused = !s.paused
 	}
 	if s.justPressed(ebiten.KeyEqual) || s.justPressed(ebiten.KeyKPAdd) {
-		s.simSpeed *= 1.5
-		if s.simSpeed > 4.0 {
-			s.simSpeed = 4.0
-		}
+		s.perform(world, sandbox.Action{Kind: sandbox.ActionSpeed, Value: math.Min(s.simSpeed*1.5, 4.0)})
 	}
 	if s.justPressed(ebiten.KeyMinus) || s.justPressed(ebiten.KeyKPSubtract) {
-		s.simSpeed /= 1.5
-		if s.simSpeed < 0.25 {
-			s.simSpeed = 0.25
-		}
+		s.perform(world, sandbox.Action{Kind: sandbox.ActionSpeed, Value: math.Max(s.simSpeed/1.5, 0.25)})
 	}
 }
 
+// brushMass returns the mass of a particle launched with the current brush.
+func (s *InputState) brushMass() float64 {
+	r := float64(s.nextRadius)
+	return s.nextMaterial.Props().Density * r * r
+}
+
+// handleSizeControl changes the brush size with [ and ] and cycles its
+// material with Tab.
 func (s *InputState) handleSizeControl() {
+	if s.justPressed(ebiten.KeyTab) {
+		s.nextMaterial = (s.nextMaterial + 1) % physics.NumMaterials
+	}
+	if s.justPressed(ebiten.KeyQ) {
+		s.nextKind = (s.nextKind + 1) % physics.NumKinds
+	}
 	if s.justPressed(ebiten.KeyBracketRight) {
 		s.nextRadius += 3
 		if s.nextRadius > 60 {
@@ -307,7 +580,7 @@ func (s *InputState) handleCamera(cam *Camera) {
 	}
 }
 
-func (s *InputState) handleSelection(world *World, cam *Camera) {
+func (s *InputState) handleSelection(world *physics.World, cam *Camera) {
 	rightDown := ebiten.IsMouseButtonPressed(ebiten.MouseButtonRight)
 	justRightClicked := rightDown && !s.prevRightClick
 	s.prevRightClick = rightDown
@@ -315,22 +588,36 @@ func (s *InputState) handleSelection(world *World, cam *Camera) {
 	if justRightClicked {
 		cx, cy := ebiten.CursorPosition()
 		wx, wy := cam.ScreenToWorld(float64(cx), float64(cy))
-		obj := world.FindObject(wx, wy, 15)
-		s.selectedObj = obj
+		s.selectedID = physics.NoObject
+		if obj := world.FindObject(wx, wy, 15); obj != nil {
+			s.selectedID = obj.ID
+		}
 	}
 
-	if s.selectedObj != nil {
+	if sel := s.selected(world); sel != nil {
 		if s.justPressed(ebiten.KeyDelete) || s.justPressed(ebiten.KeyBackspace) {
-			world.RemoveObject(s.selectedObj)
-			s.selectedObj = nil
+			s.perform(world, sandbox.Action{Kind: sandbox.ActionDelete, ID: sel.ID})
+			s.selectedID = physics.NoObject
 		}
 		if s.justPressed(ebiten.KeySpace) {
-			s.selectedObj.pinned = !s.selectedObj.pinned
+			s.perform(world, sandbox.Action{Kind: sandbox.ActionPin, ID: sel.ID})
+		}
+		if s.justPressed(ebiten.KeyS) {
+			s.perform(world, sandbox.Action{Kind: sandbox.ActionScatterSwarm, ID: sel.ID})
 		}
 	}
+	if s.justPressed(ebiten.KeyC) && world.Swarm().Len() > 0 {
+		s.perform(world, sandbox.Action{Kind: sandbox.ActionClearSwarm})
+	}
 }
 
-func (s *InputState) handleMouse(world *World, cam *Camera) {
+// selected returns the selected object, or nil if nothing is selected or it
+// has since merged away or been removed.
+func (s *InputState) selected(world *physics.World) *physics.Object {
+	return world.Object(s.selectedID)
+}
+
+func (s *InputState) handleMouse(world *physics.World, cam *Camera) {
 	if s.panning {
 		return
 	}
@@ -339,11 +626,16 @@ func (s *InputState) handleMouse(world *World, cam *Camera) {
 	wx, wy := cam.ScreenToWorld(float64(cx), float64(cy))
 
 	if ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
-		if !s.aiming && !s.dragging {
+		if !s.aiming && !s.dragging && !s.linking {
 			obj := world.FindObject(wx, wy, 15)
-			if obj != nil {
+			kind, modified := linkModifier()
+			if obj != nil && modified {
+				s.linking = true
+				s.linkFrom = obj.ID
+				s.linkKind = kind
+			} else if obj != nil {
 				s.dragging = true
-				s.dragObj = obj
+				s.dragID = obj.ID
 			} else {
 				s.aiming = true
 				s.aimStartX = wx
@@ -351,28 +643,55 @@ func (s *InputState) handleMouse(world *World, cam *Camera) {
 			}
 		}
 
-		if s.dragging && s.dragObj != nil {
-			s.dragObj.x = wx
-			s.dragObj.y = wy
-			s.dragObj.velocityX = 0
-			s.dragObj.velocityY = 0
+		obj := world.Object(s.dragID)
+		moved := obj != nil && (obj.X != wx || obj.Y != wy || obj.VelocityX != 0 || obj.VelocityY != 0)
+		if s.dragging && moved {
+			s.perform(world, sandbox.Action{Kind: sandbox.ActionDrag, ID: s.dragID, X: wx, Y: wy})
 		}
 	} else {
-		if s.aiming {
+		if s.linking {
+			if obj := world.FindObject(wx, wy, 15); obj != nil && obj.ID != s.linkFrom {
+				s.perform(world, sandbox.Action{Kind: sandbox.ActionConnect, ID: s.linkFrom, Other: obj.ID, Constraint: s.linkKind.String()})
+			}
+		} else if s.aiming && s.nextKind != physics.KindBody {
+			s.perform(world, s.placeAction(wx, wy))
+		} else if s.aiming {
 			dx := wx - s.aimStartX
 			dy := wy - s.aimStartY
 			launchScale := 0.05
-			obj := world.AddObject(s.aimStartX, s.aimStartY, s.nextRadius)
-			obj.velocityX = -dx * launchScale
-			obj.velocityY = -dy * launchScale
+			s.perform(world, sandbox.Action{
+				Kind:     sandbox.ActionLaunch,
+				X:        s.aimStartX,
+				Y:        s.aimStartY,
+				VX:       -dx * launchScale,
+				VY:       -dy * launchScale,
+				Radius:   float64(s.nextRadius),
+				Material: s.nextMaterial.String(),
+			})
 		}
 
 		s.aiming = false
 		s.dragging = false
-		s.dragObj = nil
+		s.dragID = physics.NoObject
+		s.linking = false
 	}
 }
 
+// linkModifier returns the constraint a drag from one object to another
+// creates while a modifier is held: [Shift] a spring, [Ctrl] a rod and
+// [Alt] a rope.
+func linkModifier() (physics.ConstraintKind, bool) {
+	switch {
+	case ebiten.IsKeyPressed(ebiten.KeyShift):
+		return physics.ConstraintSpring, true
+	case ebiten.IsKeyPressed(ebiten.KeyControl):
+		return physics.ConstraintRod, true
+	case ebiten.IsKeyPressed(ebiten.KeyAlt):
+		return physics.ConstraintRope, true
+	}
+	return 0, false
+}
+
 func (s *InputState) cursorWorld(cam *Camera) (float64, float64) {
 	cx, cy := ebiten.CursorPosition()
 	return cam.ScreenToWorld(float64(cx), float64(cy))
@@ -0,0 +1,486 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"sort"
+
+	"github.com/bondar-pavel/gravity/physics"
+	"github.com/bondar-pavel/gravity/sandbox"
+)
+
+// missionsDir holds the mission definitions, one JSON file each, played in
+// file name order. When it is missing the copies built into the binary are
+// used.
+const missionsDir = "missions"
+
+//go:embed missions/*.json
+var builtinMissions embed.FS
+
+type MissionState int
+
+const (
+	MissionFlying   MissionState = iota // craft under the player's control
+	MissionComplete                     // objective met
+	MissionFailed                       // craft crashed or was lost
+)
+
+// ObjectiveKind is what a mission asks of the craft.
+type ObjectiveKind int
+
+const (
+	ObjectiveCircularize ObjectiveKind = iota // orbit Around with periapsis and apoapsis within Tolerance of Radius
+	ObjectiveTransfer                         // the same, at the orbital radius of body Target
+	ObjectiveRendezvous                       // within Distance of body Target, moving slower than Speed relative to it
+	numObjectiveKinds
+)
+
+func (k ObjectiveKind) String() string {
+	switch k {
+	case ObjectiveTransfer:
+		return "Transfer"
+	case ObjectiveRendezvous:
+		return "Rendezvous"
+	default:
+		return "Circularize"
+	}
+}
+
+func (k *ObjectiveKind) UnmarshalText(text []byte) error {
+	kinds := make([]ObjectiveKind, numObjectiveKinds)
+	for i := range kinds {
+		kinds[i] = ObjectiveKind(i)
+	}
+	v, err := sandbox.ParseEnum(string(text), kinds...)
+	if err != nil {
+		return err
+	}
+	*k = v
+	return nil
+}
+
+// Mission is one mission as stored in missionsDir. Bodies are placed like
+// preset bodies, relative to the world centre, and may orbit earlier bodies;
+// the craft starts on an orbit of its own with its engine off. The objective
+// is met once the engine is off and its conditions hold; delta-v spent
+// against Par decides the stars.
+type Mission struct {
+	Name      string        `json:"name"`
+	Briefing  string        `json:"briefing"`
+	Zoom      float64       `json:"zoom,omitempty"`
+	Bodies    []MissionBody `json:"bodies"`
+	Craft     MissionCraft  `json:"craft"`
+	Objective Objective     `json:"objective"`
+	Par       float64       `json:"par"` // delta-v for three stars
+}
+
+// MissionBody is a body in a mission, see sandbox.PresetBody.
+type MissionBody struct {
+	X        float64              `json:"x,omitempty"`
+	Y        float64              `json:"y,omitempty"`
+	VX       float64              `json:"vx,omitempty"`
+	VY       float64              `json:"vy,omitempty"`
+	Radius   float64              `json:"radius"`
+	Mass     float64              `json:"mass,omitempty"`     // 0 = density × radius²
+	Material string               `json:"material,omitempty"` // empty is rock
+	Color    [3]byte              `json:"color"`
+	Pinned   bool                 `json:"pinned,omitempty"`
+	Orbit    *sandbox.PresetOrbit `json:"orbit,omitempty"`
+}
+
+// MissionCraft is the player's rocket.
+type MissionCraft struct {
+	Radius float64             `json:"radius"`
+	Fuel   float64             `json:"fuel"` // share of the craft's mass that is propellant
+	Orbit  sandbox.PresetOrbit `json:"orbit"`
+}
+
+// Objective is a mission's goal. Around and Target are body indices.
+type Objective struct {
+	Kind      ObjectiveKind `json:"kind"`
+	Around    int           `json:"around"`
+	Target    int           `json:"target,omitempty"`
+	Radius    float64       `json:"radius,omitempty"`
+	Tolerance float64       `json:"tolerance,omitempty"`
+	Distance  float64       `json:"distance,omitempty"`
+	Speed     float64       `json:"speed,omitempty"`
+}
+
+// loadMissions reads every mission in missionsDir, or the built-in ones.
+func loadMissions() ([]Mission, error) {
+	fsys, err := fs.Sub(builtinMissions, missionsDir)
+	if err != nil {
+		return nil, err
+	}
+	if fi, err := os.Stat(missionsDir); err == nil && fi.IsDir() {
+		fsys = os.DirFS(missionsDir)
+	}
+	names, err := fs.Glob(fsys, "*.json")
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+
+	missions := make([]Mission, 0, len(names))
+	for _, name := range names {
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		var m Mission
+		if err := json.Unmarshal(data, &m); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if err := m.validate(); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		missions = append(missions, m)
+	}
+	if len(missions) == 0 {
+		return nil, fmt.Errorf("no missions in %s", missionsDir)
+	}
+	return missions, nil
+}
+
+// validate checks that the mission's body indices and materials are usable.
+func (m *Mission) validate() error {
+	inRange := func(around []int, n int) bool {
+		for _, i := range around {
+			if i < 0 || i >= n {
+				return false
+			}
+		}
+		return len(around) > 0
+	}
+	for i, b := range m.Bodies {
+		if _, err := sandbox.ParseMaterial(b.Material); err != nil {
+			return fmt.Errorf("body %d material: %w", i, err)
+		}
+		if b.Orbit != nil && !inRange(b.Orbit.Around, i) {
+			return fmt.Errorf("body %d orbits a body not placed before it", i)
+		}
+	}
+	n := len(m.Bodies)
+	if !inRange(m.Craft.Orbit.Around, n) {
+		return fmt.Errorf("craft orbits a body out of range")
+	}
+	if m.Craft.Fuel <= 0 || m.Craft.Fuel >= 1 {
+		return fmt.Errorf("craft fuel %g must be between 0 and 1", m.Craft.Fuel)
+	}
+	o := m.Objective
+	if !inRange([]int{o.Around, o.Target}, n) {
+		return fmt.Errorf("objective names a body out of range")
+	}
+	switch o.Kind {
+	case ObjectiveCircularize, ObjectiveTransfer:
+		if o.Kind == ObjectiveCircularize && o.Radius <= 0 {
+			return fmt.Errorf("objective radius %g must be positive", o.Radius)
+		}
+		if o.Tolerance <= 0 {
+			return fmt.Errorf("objective tolerance %g must be positive", o.Tolerance)
+		}
+	case ObjectiveRendezvous:
+		if o.Distance <= 0 || o.Speed <= 0 {
+			return fmt.Errorf("objective distance %g and speed %g must be positive", o.Distance, o.Speed)
+		}
+	}
+	return nil
+}
+
+type MissionMode struct {
+	active         bool
+	state          MissionState
+	currentMission int
+	missions       []Mission
+	loadErr        error
+
+	// Current attempt
+	craftID     physics.ObjectID
+	aroundID    physics.ObjectID // the objective's bodies
+	targetID    physics.ObjectID
+	goalRadius  float64   // circularize and transfer: the orbit to reach
+	failure     string    // why the mission failed
+	result      float64   // delta-v spent on the completed attempt
+	bestDeltaV  []float64 // lowest delta-v per mission; 0 = not yet completed
+	newBest     bool
+	resultTimer int
+
+	// Saved sandbox state
+	savedObjects     []*physics.Object
+	savedConstraints []physics.Constraint
+	savedSwarm       physics.Swarm // positions and velocities only
+	savedMerge       bool
+	savedFragment    bool
+	savedTidal       bool
+	savedFriction    bool
+	savedCollide     bool
+	savedEjecta      bool
+	savedAdaptive    bool
+	savedGravity     physics.GravityMode
+	savedSolver      physics.GravitySolver
+	savedIntegrator  physics.IntegratorKind
+
+	unsubscribe func() // detaches handleEvent from the world
+}
+
+func newMissionMode() *MissionMode {
+	missions, err := loadMissions()
+	return &MissionMode{
+		missions:   missions,
+		loadErr:    err,
+		bestDeltaV: make([]float64, len(missions)),
+	}
+}
+
+// Enter starts the mission mode. Missions fly under Newtonian gravity, so
+// orbits are the conics the objectives are stated in; merging, fragmentation,
+// tidal disruption and friction are off, and collisions are on so a crash is
+// noticed. Solver, integrator and timestep are fixed too, so a mission plays
+// the same whatever the sandbox was set to. The sandbox's objects,
+// constraints, test particles and settings are put back by Exit.
+func (m *MissionMode) Enter(world *physics.World, cam *Camera) error {
+	if m.loadErr != nil {
+		return m.loadErr
+	}
+	m.savedObjects = make([]*physics.Object, len(world.Objects()))
+	copy(m.savedObjects, world.Objects())
+	m.savedConstraints = append([]physics.Constraint(nil), world.Constraints()...)
+	sw := world.Swarm()
+	m.savedSwarm = physics.Swarm{
+		X:  append([]float64(nil), sw.X...),
+		Y:  append([]float64(nil), sw.Y...),
+		VX: append([]float64(nil), sw.VX...),
+		VY: append([]float64(nil), sw.VY...),
+	}
+	m.savedMerge = world.MergeOnCollision
+	m.savedFragment = world.FragmentOnCollision
+	m.savedTidal = world.TidalDisruption
+	m.savedFriction = world.FrictionEnabled
+	m.savedCollide = world.BounceOnParticleCollision
+	m.savedEjecta = world.PhysicalEjecta
+	m.savedAdaptive = world.AdaptiveTimestep
+	m.savedGravity = world.GravityMode
+	m.savedSolver = world.Solver
+	m.savedIntegrator = world.IntegratorKind()
+
+	m.active = true
+	world.MergeOnCollision = false
+	world.FragmentOnCollision = false
+	world.TidalDisruption = false
+	world.FrictionEnabled = false
+	world.BounceOnParticleCollision = true
+	world.PhysicalEjecta = false
+	world.AdaptiveTimestep = false
+	world.GravityMode = physics.GravityNewtonian
+	world.Solver = physics.SolverDirect
+	world.SetIntegrator(physics.IntegratorVerlet)
+	m.unsubscribe = world.Subscribe(func(e physics.Event) { m.handleEvent(world, e) })
+	m.loadMission(world, cam)
+	return nil
+}
+
+func (m *MissionMode) Exit(world *physics.World, cam *Camera) {
+	m.active = false
+	m.craftID = physics.NoObject
+	m.unsubscribe()
+	m.unsubscribe = nil
+
+	world.MergeOnCollision = m.savedMerge
+	world.FragmentOnCollision = m.savedFragment
+	world.TidalDisruption = m.savedTidal
+	world.FrictionEnabled = m.savedFriction
+	world.BounceOnParticleCollision = m.savedCollide
+	world.PhysicalEjecta = m.savedEjecta
+	world.AdaptiveTimestep = m.savedAdaptive
+	world.GravityMode = m.savedGravity
+	world.Solver = m.savedSolver
+	world.SetIntegrator(m.savedIntegrator)
+	// After the settings, so the stored accelerations are the sandbox's
+	world.SetConstraints(m.savedConstraints)
+	world.ReplaceObjects(m.savedObjects)
+	world.ClearSwarm()
+	sw := &m.savedSwarm
+	for i := range sw.X {
+		world.AddTestParticle(sw.X[i], sw.Y[i], sw.VX[i], sw.VY[i])
+	}
+	m.savedObjects = nil
+	m.savedConstraints = nil
+	m.savedSwarm = physics.Swarm{}
+	cam.Reset()
+}
+
+func (m *MissionMode) loadMission(world *physics.World, cam *Camera) {
+	mission := m.missions[m.currentMission]
+	world.Clear()
+
+	cx, cy := world.Config().CenterX, world.Config().CenterY
+	for _, b := range mission.Bodies {
+		obj := world.AddObject(cx+b.X, cy+b.Y, b.Radius)
+		material, _ := sandbox.ParseMaterial(b.Material)
+		obj.SetMaterial(material)
+		if b.Mass > 0 {
+			obj.Mass = b.Mass
+		}
+		obj.Pinned = b.Pinned
+		if b.Color != ([3]byte{}) {
+			obj.Color = b.Color
+		}
+		obj.VelocityX, obj.VelocityY = b.VX, b.VY
+		if b.Orbit != nil {
+			sandbox.PlaceOnOrbit(world, obj, *b.Orbit)
+		}
+	}
+
+	craft := world.AddObject(cx, cy, mission.Craft.Radius)
+	craft.Color = [3]byte{230, 230, 240}
+	sandbox.PlaceOnOrbit(world, craft, mission.Craft.Orbit)
+	craft.FitEngine(mission.Craft.Fuel)
+	m.craftID = craft.ID
+
+	goal := mission.Objective
+	around, target := world.Objects()[goal.Around], world.Objects()[goal.Target]
+	m.aroundID, m.targetID = around.ID, target.ID
+	m.goalRadius = goal.Radius
+	if goal.Kind == ObjectiveTransfer {
+		m.goalRadius = math.Hypot(target.X-around.X, target.Y-around.Y)
+	}
+
+	world.SetIntegrator(world.IntegratorKind())
+	cam.Reset()
+	if mission.Zoom > 0 {
+		cam.zoom = mission.Zoom
+	}
+
+	m.state = MissionFlying
+	m.failure = ""
+	m.newBest = false
+	m.resultTimer = 0
+}
+
+func (m *MissionMode) ChangeMission(delta int, world *physics.World, cam *Camera) {
+	m.currentMission = (m.currentMission + delta + len(m.missions)) % len(m.missions)
+	m.loadMission(world, cam)
+}
+
+func (m *MissionMode) RetryMission(world *physics.World, cam *Camera) {
+	m.loadMission(world, cam)
+}
+
+// Update is called each physics tick while the mission mode is active.
+func (m *MissionMode) Update(world *physics.World) {
+	if !m.active {
+		return
+	}
+
+	switch m.state {
+	case MissionFlying:
+		if craft := m.Craft(world); craft != nil && !craft.Firing() && m.objectiveMet(world, craft) {
+			m.complete(world, craft)
+		}
+	case MissionComplete, MissionFailed:
+		m.resultTimer++
+	}
+}
+
+// objectiveMet reports whether the craft is where the objective wants it.
+func (m *MissionMode) objectiveMet(world *physics.World, craft *physics.Object) bool {
+	goal := m.CurrentMission().Objective
+	switch goal.Kind {
+	case ObjectiveRendezvous:
+		dist, speed, ok := m.Approach(world, craft)
+		return ok && dist <= goal.Distance && speed <= goal.Speed
+	default:
+		orbit, ok := m.CraftOrbit(world, craft)
+		return ok && orbit.Bound &&
+			math.Abs(orbit.Periapsis-m.goalRadius) <= goal.Tolerance &&
+			math.Abs(orbit.Apoapsis-m.goalRadius) <= goal.Tolerance
+	}
+}
+
+// CraftOrbit returns the craft's orbit around the objective's central body,
+// and false if that body is gone.
+func (m *MissionMode) CraftOrbit(world *physics.World, craft *physics.Object) (physics.Orbit, bool) {
+	around := world.Object(m.aroundID)
+	if around == nil {
+		return physics.Orbit{}, false
+	}
+	return world.OrbitAround(craft, around), true
+}
+
+// Approach returns the craft's distance and speed relative to the rendezvous
+// target, and false if the target is gone.
+func (m *MissionMode) Approach(world *physics.World, craft *physics.Object) (float64, float64, bool) {
+	t := world.Object(m.targetID)
+	if t == nil {
+		return 0, 0, false
+	}
+	return math.Hypot(craft.X-t.X, craft.Y-t.Y),
+		math.Hypot(craft.VelocityX-t.VelocityX, craft.VelocityY-t.VelocityY), true
+}
+
+// handleEvent fails the mission when the craft touches anything or leaves
+// the world.
+func (m *MissionMode) handleEvent(world *physics.World, e physics.Event) {
+	if m.state != MissionFlying || m.craftID == physics.NoObject {
+		return
+	}
+	id := m.craftID
+	switch e.Kind {
+	case physics.EventCollision, physics.EventMerge, physics.EventFragment, physics.EventDisrupted,
+		physics.EventAbsorbed:
+		if e.A == id || e.B == id {
+			m.fail(world, "crashed")
+		}
+	case physics.EventRemoved:
+		if e.A == id {
+			m.craftID = physics.NoObject
+			m.fail(world, "lost in space")
+		}
+	}
+}
+
+func (m *MissionMode) fail(world *physics.World, reason string) {
+	m.state = MissionFailed
+	m.failure = reason
+	m.resultTimer = 0
+	world.RemoveID(m.craftID)
+	m.craftID = physics.NoObject
+}
+
+// complete ends the mission, keeping the craft on its new orbit.
+func (m *MissionMode) complete(world *physics.World, craft *physics.Object) {
+	m.state = MissionComplete
+	m.resultTimer = 0
+	m.result = craft.Engine.Used
+	if best := m.bestDeltaV[m.currentMission]; best == 0 || m.result < best {
+		m.bestDeltaV[m.currentMission] = m.result
+		m.newBest = true
+	}
+}
+
+// StarRating scores delta-v spent against the mission's par.
+func (m *MissionMode) StarRating(used float64) int {
+	par := m.CurrentMission().Par
+	switch {
+	case used <= par:
+		return 3
+	case used <= 1.25*par:
+		return 2
+	case used <= 1.5*par:
+		return 1
+	}
+	return 0
+}
+
+// Craft returns the player's rocket, or nil once it is gone.
+func (m *MissionMode) Craft(world *physics.World) *physics.Object {
+	return world.Object(m.craftID)
+}
+
+func (m *MissionMode) CurrentMission() Mission {
+	return m.missions[m.currentMission]
+}
@@ -0,0 +1,65 @@
+package physics
+
+import "math"
+
+// accretionDecay is the fraction of a black hole's Accretion kept from one
+// tick to the next, so the glow of a swallowed body fades over about a
+// hundred ticks.
+const accretionDecay = 0.98
+
+// absorbIntoHorizons lets every black hole swallow the bodies whose centres
+// have crossed its event horizon, and the lighter black holes whose horizons
+// touch its own. A black hole's Radius is its horizon; it has no surface, so
+// bodies pass into it rather than bouncing off. Absorption goes through
+// MergeFrom and so conserves mass and momentum, except that a pinned black
+// hole stays where it is.
+func (w *World) absorbIntoHorizons() {
+	var swallowed []*Object
+	var gone map[*Object]bool
+	for _, bh := range w.objects {
+		if bh.Material != MaterialBlackHole || gone[bh] {
+			continue
+		}
+		for _, obj := range w.objects {
+			if obj == bh || gone[obj] || obj.Kind != KindBody {
+				continue
+			}
+			reach := bh.Radius
+			if obj.Material == MaterialBlackHole {
+				if obj.Mass > bh.Mass {
+					continue // the heavier hole does the swallowing
+				}
+				reach += obj.Radius
+			}
+			dx, dy := obj.X-bh.X, obj.Y-bh.Y
+			if dx*dx+dy*dy >= reach*reach {
+				continue
+			}
+			w.absorb(bh, obj)
+			swallowed = append(swallowed, obj)
+			if gone == nil {
+				gone = make(map[*Object]bool)
+			}
+			gone[obj] = true
+		}
+	}
+	for _, obj := range swallowed {
+		w.RemoveObject(obj)
+	}
+}
+
+// absorb merges obj into the black hole bh and reports it as an
+// EventAbsorbed; the caller removes obj. Nothing escapes the horizon, so
+// there is no flash or ejecta, only the accretion glow.
+func (w *World) absorb(bh, obj *Object) {
+	speed := math.Hypot(bh.VelocityX-obj.VelocityX, bh.VelocityY-obj.VelocityY)
+	w.emit(Event{Kind: EventAbsorbed, A: bh.ID, B: obj.ID, X: obj.X, Y: obj.Y, Speed: speed})
+
+	x, y, vx, vy := bh.X, bh.Y, bh.VelocityX, bh.VelocityY
+	bh.MergeFrom(obj)
+	if bh.Pinned {
+		bh.X, bh.Y, bh.VelocityX, bh.VelocityY = x, y, vx, vy
+	}
+	bh.MergeTimer, bh.MergeFlash = 0, 0
+	bh.Accretion += obj.Mass
+}
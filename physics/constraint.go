@@ -0,0 +1,192 @@
+package physics
+
+import "math"
+
+// ConstraintKind selects how a Constraint holds two objects together.
+type ConstraintKind int
+
+const (
+	ConstraintSpring ConstraintKind = iota // damped spring pulling towards Length
+	ConstraintRod                          // rigid: the distance stays Length
+	ConstraintRope                         // the distance stays at most Length; slack when shorter
+	NumConstraintKinds
+)
+
+func (k ConstraintKind) String() string {
+	switch k {
+	case ConstraintRod:
+		return "Rod"
+	case ConstraintRope:
+		return "Rope"
+	default:
+		return "Spring"
+	}
+}
+
+// Default spring tuning for Connect, relative to the connected masses.
+const (
+	DefaultSpringRate    = 0.02 // squared angular frequency ω² of the spring, per tick²
+	DefaultSpringDamping = 0.1  // damping ratio: under 1 the spring oscillates
+)
+
+// constraintIterations is how many times rods and ropes are projected per
+// substep; chains of several links need more than one pass to settle.
+const constraintIterations = 4
+
+// Constraint connects objects A and B. Springs act as a force, so gravity
+// and the integrator see them; rods and ropes are enforced after each
+// substep by moving the two ends and removing their relative velocity along
+// the link. Either end may be pinned. A constraint is dropped when either of
+// its objects leaves the world.
+type Constraint struct {
+	Kind      ConstraintKind
+	A, B      ObjectID
+	Length    float64 // rest length (spring), length (rod) or maximum length (rope)
+	Stiffness float64 // spring: force per pixel of stretch
+	Damping   float64 // spring: force per pixel/tick of stretching speed
+}
+
+// Constraints returns the world's constraints. The slice must not be
+// modified; use AddConstraint and SetConstraints.
+func (w *World) Constraints() []Constraint { return w.constraints }
+
+// AddConstraint adds c.
+func (w *World) AddConstraint(c Constraint) {
+	w.constraints = append(w.constraints, c)
+}
+
+// SetConstraints replaces all constraints with a copy of cs.
+func (w *World) SetConstraints(cs []Constraint) {
+	w.constraints = append(w.constraints[:0], cs...)
+}
+
+// Connect links a and b at their current distance and returns the new
+// constraint. Springs are tuned from the objects' masses with
+// DefaultSpringRate and DefaultSpringDamping; a pinned end counts as
+// infinitely heavy.
+func (w *World) Connect(kind ConstraintKind, a, b *Object) Constraint {
+	c := Constraint{
+		Kind:   kind,
+		A:      a.ID,
+		B:      b.ID,
+		Length: math.Hypot(b.X-a.X, b.Y-a.Y),
+	}
+	if kind == ConstraintSpring {
+		// Reduced mass: the spring's frequency depends on both ends
+		if wsum := inverseMass(a) + inverseMass(b); wsum > 0 {
+			mu := 1 / wsum
+			c.Stiffness = DefaultSpringRate * mu
+			c.Damping = 2 * DefaultSpringDamping * math.Sqrt(c.Stiffness*mu)
+		}
+	}
+	w.AddConstraint(c)
+	return c
+}
+
+// inverseMass returns 1/mass, or 0 for objects that do not move.
+func inverseMass(o *Object) float64 {
+	if o.Pinned || o.Mass <= 0 {
+		return 0
+	}
+	return 1 / o.Mass
+}
+
+// dropConstraints removes the constraints on obj.
+func (w *World) dropConstraints(obj ObjectID) {
+	kept := w.constraints[:0]
+	for _, c := range w.constraints {
+		if c.A != obj && c.B != obj {
+			kept = append(kept, c)
+		}
+	}
+	clear(w.constraints[len(kept):])
+	w.constraints = kept
+}
+
+// ends returns the two objects of c, or nil if either has left the world.
+func (w *World) ends(c Constraint) (*Object, *Object) {
+	a, b := w.Object(c.A), w.Object(c.B)
+	if a == nil || b == nil {
+		return nil, nil
+	}
+	return a, b
+}
+
+// addSpringForces adds the accelerations of every spring to accels, which
+// holds one entry per object. Pinned ends are left alone.
+func (w *World) addSpringForces(accels []accel) {
+	for _, c := range w.constraints {
+		if c.Kind != ConstraintSpring {
+			continue
+		}
+		a, b := w.ends(c)
+		if a == nil {
+			continue
+		}
+		dx, dy := b.X-a.X, b.Y-a.Y
+		d := math.Hypot(dx, dy)
+		if d == 0 {
+			continue
+		}
+		nx, ny := dx/d, dy/d
+		stretchSpeed := (b.VelocityX-a.VelocityX)*nx + (b.VelocityY-a.VelocityY)*ny
+		f := c.Stiffness*(d-c.Length) + c.Damping*stretchSpeed // pulls the ends together
+		if wa := inverseMass(a); wa > 0 {
+			i := w.index[a.ID]
+			accels[i].ax += f * nx * wa
+			accels[i].ay += f * ny * wa
+		}
+		if wb := inverseMass(b); wb > 0 {
+			i := w.index[b.ID]
+			accels[i].ax -= f * nx * wb
+			accels[i].ay -= f * ny * wb
+		}
+	}
+}
+
+// solveConstraints enforces rods and ropes. Each pass moves the two ends of
+// every violated link apart or together in inverse proportion to their
+// masses and removes their relative velocity along it, so momentum is
+// conserved.
+func (w *World) solveConstraints() {
+	for iter := 0; iter < constraintIterations; iter++ {
+		for _, c := range w.constraints {
+			if c.Kind == ConstraintSpring {
+				continue
+			}
+			a, b := w.ends(c)
+			if a == nil {
+				continue
+			}
+			wa, wb := inverseMass(a), inverseMass(b)
+			if wa+wb == 0 {
+				continue
+			}
+			dx, dy := b.X-a.X, b.Y-a.Y
+			d := math.Hypot(dx, dy)
+			if d == 0 {
+				continue
+			}
+			stretch := d - c.Length
+			if c.Kind == ConstraintRope && stretch <= 0 {
+				continue // slack
+			}
+			nx, ny := dx/d, dy/d
+			share := stretch / (wa + wb)
+			a.X += nx * share * wa
+			a.Y += ny * share * wa
+			b.X -= nx * share * wb
+			b.Y -= ny * share * wb
+
+			stretchSpeed := (b.VelocityX-a.VelocityX)*nx + (b.VelocityY-a.VelocityY)*ny
+			if c.Kind == ConstraintRope && stretchSpeed <= 0 {
+				continue // already coming back
+			}
+			j := stretchSpeed / (wa + wb)
+			a.VelocityX += nx * j * wa
+			a.VelocityY += ny * j * wa
+			b.VelocityX -= nx * j * wb
+			b.VelocityY -= ny * j * wb
+		}
+	}
+}
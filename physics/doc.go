@@ -0,0 +1,21 @@
+// Package physics is the gravity sandbox's simulation engine: softened
+// N-body gravity with pluggable integrators, an optional Barnes-Hut solver,
+// collisions with bounce, merge or fragmentation, tidal disruption, black
+// holes that swallow what crosses their horizon, and conservation
+// diagnostics.
+//
+// A World is created from a Config holding its constants and advanced one
+// tick at a time with StepPhysics:
+//
+//	w := physics.NewWorld(physics.DefaultConfig())
+//	sun := w.AddObject(800, 600, 30)
+//	sun.Pinned = true
+//	planet := w.AddObject(1000, 600, 5)
+//	planet.VelocityY = -w.CircularSpeed(200, sun.Mass, planet.Mass)
+//	for i := 0; i < 1000; i++ {
+//		w.StepPhysics()
+//	}
+//
+// Objects are plain structs whose fields may be read at any time and
+// written between steps. The package does no rendering or input handling.
+package physics
@@ -0,0 +1,108 @@
+package physics
+
+// EventKind identifies what an Event reports.
+type EventKind int
+
+const (
+	EventSpawned    EventKind = iota // A was added to the world
+	EventCollision                   // A and B touched and bounced apart
+	EventMerge                       // B was absorbed into A
+	EventCulled                      // A drifted past Config.CullDistance
+	EventRemoved                     // A left the world, for any reason
+	EventFragment                    // A and B shattered; A is the largest remnant, B is removed
+	EventDisrupted                   // A was torn apart inside B's Roche limit and is removed
+	EventAbsorbed                    // B crossed the event horizon of black hole A and is removed
+	EventTeleported                  // A went through wormhole mouth B and now is at (X, Y)
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case EventCollision:
+		return "Collision"
+	case EventMerge:
+		return "Merge"
+	case EventCulled:
+		return "Culled"
+	case EventRemoved:
+		return "Removed"
+	case EventFragment:
+		return "Fragment"
+	case EventDisrupted:
+		return "Disrupted"
+	case EventAbsorbed:
+		return "Absorbed"
+	case EventTeleported:
+		return "Teleported"
+	default:
+		return "Spawned"
+	}
+}
+
+// Event is something that happened to objects in a world. A merge,
+// fragment, disruption, absorption or cull is always followed by an
+// EventRemoved for the object that left, and fragments arrive as
+// EventSpawned, so listeners that only track membership need not handle the
+// specific causes.
+type Event struct {
+	Kind  EventKind
+	Tick  int      // World.Tick of the step it happened in
+	A, B  ObjectID // B is NoObject for single-object events
+	X, Y  float64  // contact point, merge point, or object position
+	Speed float64  // relative speed of A and B at impact; 0 for single-object events
+}
+
+// EventHandler receives world events. Handlers run after the step that
+// produced the events has finished, so they may add or remove objects.
+type EventHandler func(Event)
+
+type subscriber struct {
+	id      int
+	handler EventHandler
+}
+
+// Subscribe registers h for every event the world emits and returns a
+// function that removes it again. Handlers are called in subscription order.
+func (w *World) Subscribe(h EventHandler) (unsubscribe func()) {
+	w.nextSubscriber++
+	id := w.nextSubscriber
+	w.subscribers = append(w.subscribers, subscriber{id, h})
+	return func() {
+		for i, s := range w.subscribers {
+			if s.id == id {
+				// Copy rather than shift in place: a flush may be ranging over the old slice
+				w.subscribers = append(w.subscribers[:i:i], w.subscribers[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// emit queues e. Outside StepPhysics it is delivered immediately; during a
+// step delivery waits until the step is complete.
+func (w *World) emit(e Event) {
+	if len(w.subscribers) == 0 {
+		return
+	}
+	e.Tick = w.tick
+	w.pendingEvents = append(w.pendingEvents, e)
+	if !w.stepping {
+		w.flushEvents()
+	}
+}
+
+// flushEvents delivers queued events, including any emitted by the handlers
+// themselves.
+func (w *World) flushEvents() {
+	if w.flushing {
+		return // the outer flush picks up events queued by handlers
+	}
+	w.flushing = true
+	for i := 0; i < len(w.pendingEvents); i++ {
+		e := w.pendingEvents[i]
+		for _, s := range w.subscribers {
+			s.handler(e)
+		}
+	}
+	w.pendingEvents = w.pendingEvents[:0]
+	w.flushing = false
+}
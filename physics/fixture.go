@@ -0,0 +1,154 @@
+package physics
+
+import "math"
+
+// ObjectKind says what an object is. Bodies are ordinary matter; the other
+// kinds are fixtures, placed to shape the play field: they stay where they
+// are put, feel no gravity, never merge, and act on the bodies around them.
+type ObjectKind int
+
+const (
+	KindBody        ObjectKind = iota
+	KindRepulsor               // negative mass: pushes bodies away; solid, like a pinned body
+	KindWormhole               // a body entering this mouth leaves through the one named by Link
+	KindGravityZone            // uniform acceleration (FieldX, FieldY) inside Radius
+	KindDragZone               // atmosphere: bodies inside lose a fraction Drag of their speed per tick
+	NumKinds
+)
+
+func (k ObjectKind) String() string {
+	switch k {
+	case KindRepulsor:
+		return "Repulsor"
+	case KindWormhole:
+		return "Wormhole"
+	case KindGravityZone:
+		return "Gravity Zone"
+	case KindDragZone:
+		return "Drag Zone"
+	default:
+		return "Body"
+	}
+}
+
+// AddFixture places a fixture of the given kind, pinned where it is put. A
+// repulsor gets the negative of a rock body's mass for its radius; the other
+// kinds are massless, and their parameters are set on the returned object.
+// Create fixtures here rather than by setting Kind on a body: legacy gravity
+// divides by the mass, so an unpinned massless fixture would be flung away.
+func (w *World) AddFixture(kind ObjectKind, x, y, radius float64) *Object {
+	obj := w.AddObject(x, y, radius)
+	obj.Kind = kind
+	obj.Pinned = true
+	obj.Mass = 0
+	if kind == KindRepulsor {
+		obj.Mass = -radius * radius
+	}
+	return obj
+}
+
+// LinkWormholes joins two wormhole mouths, so that a body entering either
+// leaves through the other.
+func LinkWormholes(a, b *Object) {
+	a.Link, b.Link = b.ID, a.ID
+}
+
+// passable reports whether bodies pass through o rather than touching it:
+// wormhole mouths and zones.
+func (o *Object) passable() bool {
+	return o.Kind == KindWormhole || o.Kind == KindGravityZone || o.Kind == KindDragZone
+}
+
+// inside reports whether (x, y) is within o's radius.
+func (o *Object) inside(x, y float64) bool {
+	dx, dy := x-o.X, y-o.Y
+	return dx*dx+dy*dy < o.Radius*o.Radius
+}
+
+// gravityZones returns the world's gravity zones, reusing w.zones, so force
+// evaluation need not scan every object for each body.
+func (w *World) gravityZones() []*Object {
+	w.zones = w.zones[:0]
+	for _, o := range w.objects {
+		if o.Kind == KindGravityZone {
+			w.zones = append(w.zones, o)
+		}
+	}
+	return w.zones
+}
+
+// zoneAcceleration returns the acceleration the gravity zones among objects
+// add at (x, y).
+func zoneAcceleration(objects []*Object, x, y float64) (float64, float64) {
+	var ax, ay float64
+	for _, z := range objects {
+		if z.Kind == KindGravityZone && z.inside(x, y) {
+			ax += z.FieldX
+			ay += z.FieldY
+		}
+	}
+	return ax, ay
+}
+
+// applyDragZones slows the bodies inside drag zones over dt ticks, scaled
+// like friction by each body's material.
+func (w *World) applyDragZones(dt float64) {
+	for _, z := range w.objects {
+		if z.Kind != KindDragZone {
+			continue
+		}
+		for _, o := range w.objects {
+			if o.Pinned || !z.inside(o.X, o.Y) {
+				continue
+			}
+			d := math.Pow(1-z.Drag*o.Material.Props().Drag, dt)
+			o.VelocityX *= d
+			o.VelocityY *= d
+		}
+	}
+}
+
+// teleportThroughWormholes moves every body whose centre is inside a linked
+// wormhole mouth to just beyond the rim of the other mouth, heading away
+// from it, with its velocity unchanged.
+func (w *World) teleportThroughWormholes() {
+	var moved []*Object
+	for _, mouth := range w.objects {
+		if mouth.Kind != KindWormhole {
+			continue
+		}
+		exit := w.Object(mouth.Link)
+		if exit == nil {
+			continue
+		}
+		for _, o := range w.objects {
+			if o.Pinned || !mouth.inside(o.X, o.Y) {
+				continue
+			}
+			// Leave along the direction of motion, or of entry if at rest
+			ux, uy := o.VelocityX, o.VelocityY
+			if ux == 0 && uy == 0 {
+				ux, uy = o.X-mouth.X, o.Y-mouth.Y
+			}
+			if l := math.Hypot(ux, uy); l > 0 {
+				ux, uy = ux/l, uy/l
+			} else {
+				ux, uy = 1, 0
+			}
+			d := exit.Radius + o.Radius + 1
+			o.X, o.Y = exit.X+ux*d, exit.Y+uy*d
+			moved = append(moved, o)
+			w.emit(Event{Kind: EventTeleported, A: o.ID, B: mouth.ID, X: o.X, Y: o.Y})
+		}
+	}
+	if len(moved) == 0 {
+		return
+	}
+	// Verlet's stored acceleration belongs to the entry point; evaluate it
+	// at the exit the way a step does, with the active solver and springs
+	accels := w.computeAccelerations(nil)
+	for _, o := range moved {
+		a := accels[w.index[o.ID]]
+		o.ax, o.ay = a.ax, a.ay
+	}
+}
@@ -0,0 +1,36 @@
+package physics
+
+import "testing"
+
+// After a wormhole jump the stored acceleration must be the one a step would
+// compute at the exit: active solver, zones and springs included.
+func TestTeleportRefreshesAcceleration(t *testing.T) {
+	w := NewWorld(DefaultConfig())
+	w.GravityMode = GravityNewtonian
+	w.Solver = SolverBarnesHut
+	w.MergeOnCollision = false
+	star := w.AddObject(800, 600, 20)
+	star.Pinned = true
+	entry := w.AddFixture(KindWormhole, 400, 600, 15)
+	exit := w.AddFixture(KindWormhole, 1200, 600, 15)
+	LinkWormholes(entry, exit)
+	zone := w.AddFixture(KindGravityZone, 1250, 600, 60)
+	zone.FieldY = 0.05
+	body := w.AddObject(370, 600, 3)
+	body.VelocityX = 2
+	w.Connect(ConstraintSpring, body, w.AddObject(340, 600, 3))
+	w.SetIntegrator(IntegratorVerlet)
+
+	jumped := false
+	w.Subscribe(func(e Event) { jumped = jumped || e.Kind == EventTeleported && e.A == body.ID })
+	for i := 0; i < 30 && !jumped; i++ {
+		w.StepPhysics()
+	}
+	if !jumped {
+		t.Fatal("body never went through the wormhole")
+	}
+	want := w.computeAccelerations(nil)[w.index[body.ID]]
+	if body.ax != want.ax || body.ay != want.ay {
+		t.Errorf("stored acceleration (%v, %v), want (%v, %v)", body.ax, body.ay, want.ax, want.ay)
+	}
+}
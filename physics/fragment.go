@@ -0,0 +1,137 @@
+package physics
+
+import "math"
+
+// DefaultFragmentThreshold is the specific impact energy, in (pixels/tick)²,
+// above which bodies shatter when fragmentation is on. Two equal bodies
+// exceed it when they meet at more than 4 pixels/tick.
+const DefaultFragmentThreshold = 2.0
+
+const (
+	maxFragments    = 8   // fragments per impact besides the largest remnant
+	minFragmentMass = 1.0 // lightest fragment; radius 1 in rock
+)
+
+// impactEnergy returns the specific impact energy of a and b: the kinetic
+// energy of their relative motion per unit of total mass,
+// Q = ½·μ·v²/M with μ the reduced mass.
+func impactEnergy(a, b *Object) float64 {
+	total := a.Mass + b.Mass
+	if total <= 0 {
+		return 0
+	}
+	dvx := a.VelocityX - b.VelocityX
+	dvy := a.VelocityY - b.VelocityY
+	mu := a.Mass * b.Mass / total
+	return 0.5 * mu * (dvx*dvx + dvy*dvy) / total
+}
+
+// largestRemnantFraction returns the fraction of the colliding mass left in
+// the largest remnant at specific energy q for threshold qStar, following the
+// universal disruption law of Leinhardt & Stewart (2012): linear down to a
+// tenth of the mass, then a power law for super-catastrophic impacts.
+func largestRemnantFraction(q, qStar float64) float64 {
+	ratio := q / qStar
+	if ratio < 1.8 {
+		return 1 - 0.5*ratio
+	}
+	return 0.1 * math.Pow(ratio/1.8, -1.5)
+}
+
+// fragmentRadius returns the radius of a fragment of the given mass and
+// material.
+func fragmentRadius(mass float64, m Material) float64 {
+	return math.Sqrt(mass / m.Props().Density)
+}
+
+// shouldFragment reports whether the impact of a and b is violent enough to
+// shatter them. Pinned bodies never shatter, black holes swallow whatever
+// hits them, and nothing shatters without a positive FragmentThreshold.
+func (w *World) shouldFragment(a, b *Object) bool {
+	if !w.FragmentOnCollision || w.FragmentThreshold <= 0 || a.Pinned || b.Pinned ||
+		a.Material == MaterialBlackHole || b.Material == MaterialBlackHole {
+		return false
+	}
+	q := impactEnergy(a, b)
+	if q <= w.FragmentThreshold {
+		return false
+	}
+	debris := (a.Mass + b.Mass) * (1 - largestRemnantFraction(q, w.FragmentThreshold))
+	return debris >= 2*minFragmentMass
+}
+
+// fragment shatters a and b. a becomes the largest remnant at their centre of
+// mass and b must be removed by the caller; the rest of the mass leaves as a
+// symmetric ring of equal fragments flying outward. Mass, momentum and
+// angular momentum are conserved, the latter by setting the remnant and ring
+// turning as one body; a fraction Restitution² of the impact energy is kept as
+// the fragments' outward kinetic energy. It returns the new fragments.
+func (w *World) fragment(a, b *Object) []*Object {
+	total := a.Mass + b.Mass
+	q := impactEnergy(a, b)
+	dvx, dvy := a.VelocityX-b.VelocityX, a.VelocityY-b.VelocityY
+	mu := a.Mass * b.Mass / total
+
+	vx := (a.Mass*a.VelocityX + b.Mass*b.VelocityX) / total
+	vy := (a.Mass*a.VelocityY + b.Mass*b.VelocityY) / total
+	cx := (a.Mass*a.X + b.Mass*b.X) / total
+	cy := (a.Mass*a.Y + b.Mass*b.Y) / total
+	ax := (a.Mass*a.ax + b.Mass*b.ax) / total
+	ay := (a.Mass*a.ay + b.Mass*b.ay) / total
+
+	// Angular momentum about the centre of mass, orbital plus spin
+	l := a.Mass*((a.X-cx)*(a.VelocityY-vy)-(a.Y-cy)*(a.VelocityX-vx)) +
+		b.Mass*((b.X-cx)*(b.VelocityY-vy)-(b.Y-cy)*(b.VelocityX-vx)) +
+		0.5*a.Mass*a.Radius*a.Radius*a.AngularVelocity +
+		0.5*b.Mass*b.Radius*b.Radius*b.AngularVelocity
+
+	remnant := total * largestRemnantFraction(q, w.FragmentThreshold)
+	debris := total - remnant
+	n := max(2, min(maxFragments, 2+int(4*(q/w.FragmentThreshold-1)), int(debris/minFragmentMass)))
+	pieceMass := debris / float64(n)
+
+	// Kinetic energy kept in the centre-of-mass frame: ½·M_debris·v² = e²·½·μ·|Δv|²
+	e := w.Restitution
+	speed := e * math.Sqrt(mu*(dvx*dvx+dvy*dvy)/debris)
+
+	a.X, a.Y = cx, cy
+	a.VelocityX, a.VelocityY = vx, vy
+	a.Material = mergedMaterial(a.Material, a.Mass, b.Material, b.Mass)
+	a.Mass = remnant
+	a.Radius = fragmentRadius(remnant, a.Material)
+	a.MergeFlash = 1.0
+	a.Engine = Engine{} // a shattered rocket does not fly on
+
+	// Ring far enough out that no fragment touches the remnant or its neighbours
+	pieceRadius := fragmentRadius(pieceMass, a.Material)
+	dist := math.Max(a.Radius+pieceRadius+1, (pieceRadius+0.5)/math.Sin(math.Pi/float64(n)))
+
+	// The remnant and the ring rotate as one body to carry l
+	inertia := 0.5*remnant*a.Radius*a.Radius +
+		float64(n)*pieceMass*(dist*dist+0.5*pieceRadius*pieceRadius)
+	omega := l / inertia
+	a.AngularVelocity = omega
+
+	// Align the ring with the impact axis rather than a fixed direction
+	base := math.Atan2(dvy, dvx) + math.Pi/float64(n)
+
+	pieces := make([]*Object, n)
+	for k := range pieces {
+		angle := base + 2*math.Pi*float64(k)/float64(n)
+		dx, dy := math.Cos(angle), math.Sin(angle)
+		p := w.AddObject(cx+dx*dist, cy+dy*dist, pieceRadius)
+		p.Mass = pieceMass
+		p.Material = a.Material
+		p.VelocityX = vx + dx*speed - dy*omega*dist
+		p.VelocityY = vy + dy*speed + dx*omega*dist
+		p.AngularVelocity = omega
+		p.ax, p.ay = ax, ay
+		p.Color = a.Color
+		if k%2 == 1 {
+			p.Color = b.Color
+		}
+		pieces[k] = p
+	}
+	a.ax, a.ay = ax, ay
+	return pieces
+}
@@ -0,0 +1,20 @@
+package physics
+
+import "testing"
+
+// A threshold of zero would divide by zero when sizing the fragments; such
+// impacts must bounce or merge instead.
+func TestZeroFragmentThresholdDoesNotShatter(t *testing.T) {
+	w := NewWorld(DefaultConfig())
+	w.FragmentOnCollision = true
+	w.FragmentThreshold = 0
+	a := w.AddObject(400, 400, 10)
+	b := w.AddObject(425, 400, 10)
+	a.VelocityX, b.VelocityX = 5, -5
+
+	w.StepPhysics()
+
+	if n := len(w.Objects()); n > 2 {
+		t.Fatalf("%d objects after the impact, want no fragments", n)
+	}
+}
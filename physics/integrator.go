@@ -0,0 +1,201 @@
+package physics
+
+import "math"
+
+// Integrator advances every non-pinned object in a world by one timestep.
+// Implementations evaluate gravity through World.computeAccelerations so the
+// active solver and gravity mode apply to every stage.
+type Integrator interface {
+	Step(w *World, dt float64)
+}
+
+// IntegratorKind names the available integration schemes.
+type IntegratorKind int
+
+const (
+	IntegratorVerlet  IntegratorKind = iota // velocity Verlet, 2nd order symplectic
+	IntegratorEuler                         // semi-implicit (symplectic) Euler, 1st order
+	IntegratorRK4                           // classical Runge-Kutta, 4th order, not symplectic
+	IntegratorYoshida                       // Yoshida 4th order symplectic
+	NumIntegrators                          // number of kinds, for cycling through them
+)
+
+func (k IntegratorKind) String() string {
+	switch k {
+	case IntegratorEuler:
+		return "Euler"
+	case IntegratorRK4:
+		return "RK4"
+	case IntegratorYoshida:
+		return "Yoshida4"
+	default:
+		return "Verlet"
+	}
+}
+
+// NewIntegrator returns a fresh integrator of the given kind.
+func NewIntegrator(kind IntegratorKind) Integrator {
+	switch kind {
+	case IntegratorEuler:
+		return &eulerIntegrator{}
+	case IntegratorRK4:
+		return &rk4Integrator{}
+	case IntegratorYoshida:
+		return &yoshidaIntegrator{}
+	default:
+		return &verletIntegrator{}
+	}
+}
+
+type accel struct{ ax, ay float64 }
+
+// verletIntegrator is velocity Verlet. It reuses the acceleration stored on
+// each object from the previous step, so it costs one force evaluation.
+type verletIntegrator struct {
+	accels []accel
+}
+
+func (v *verletIntegrator) Step(w *World, dt float64) {
+	// Phase 1: Update positions using current velocity and acceleration
+	for _, o := range w.objects {
+		if o.Pinned {
+			continue
+		}
+		o.UpdatePositionVerlet(dt)
+	}
+
+	// Phase 2: Calculate new accelerations from updated positions
+	v.accels = w.computeAccelerations(v.accels)
+
+	// Phase 3: Update velocities using average of old and new acceleration
+	for i, o := range w.objects {
+		if o.Pinned {
+			continue
+		}
+		o.UpdateVelocityVerlet(v.accels[i].ax, v.accels[i].ay, dt)
+	}
+}
+
+// eulerIntegrator is semi-implicit Euler: kick with the current acceleration,
+// then drift with the updated velocity.
+type eulerIntegrator struct {
+	accels []accel
+}
+
+func (e *eulerIntegrator) Step(w *World, dt float64) {
+	e.accels = w.computeAccelerations(e.accels)
+	for i, o := range w.objects {
+		if o.Pinned {
+			continue
+		}
+		o.ax, o.ay = e.accels[i].ax, e.accels[i].ay
+		o.VelocityX += o.ax * dt
+		o.VelocityY += o.ay * dt
+		o.X += o.VelocityX * dt
+		o.Y += o.VelocityY * dt
+	}
+}
+
+// rk4State is one object's position and velocity (or their derivatives).
+type rk4State struct {
+	x, y, vx, vy float64
+}
+
+// rk4Integrator is classical 4th-order Runge-Kutta over the whole system.
+// Intermediate stages move the objects temporarily so forces can be
+// evaluated with the regular solver.
+type rk4Integrator struct {
+	start  []rk4State
+	k      [4][]rk4State
+	accels []accel
+}
+
+func (r *rk4Integrator) Step(w *World, dt float64) {
+	n := len(w.objects)
+	r.start = resizeStates(r.start, n)
+	for s := range r.k {
+		r.k[s] = resizeStates(r.k[s], n)
+	}
+	for i, o := range w.objects {
+		r.start[i] = rk4State{o.X, o.Y, o.VelocityX, o.VelocityY}
+	}
+
+	// Stage s evaluates the derivative at start + offsets[s] * dt * k[s-1]
+	offsets := [4]float64{0, 0.5, 0.5, 1}
+	for s := 0; s < 4; s++ {
+		if s > 0 {
+			h := offsets[s] * dt
+			for i, o := range w.objects {
+				if o.Pinned {
+					continue
+				}
+				p := r.k[s-1][i]
+				o.X = r.start[i].x + h*p.x
+				o.Y = r.start[i].y + h*p.y
+				o.VelocityX = r.start[i].vx + h*p.vx
+				o.VelocityY = r.start[i].vy + h*p.vy
+			}
+		}
+		r.accels = w.computeAccelerations(r.accels)
+		for i, o := range w.objects {
+			r.k[s][i] = rk4State{o.VelocityX, o.VelocityY, r.accels[i].ax, r.accels[i].ay}
+		}
+	}
+
+	for i, o := range w.objects {
+		if o.Pinned {
+			continue
+		}
+		k1, k2, k3, k4 := r.k[0][i], r.k[1][i], r.k[2][i], r.k[3][i]
+		o.X = r.start[i].x + dt/6*(k1.x+2*k2.x+2*k3.x+k4.x)
+		o.Y = r.start[i].y + dt/6*(k1.y+2*k2.y+2*k3.y+k4.y)
+		o.VelocityX = r.start[i].vx + dt/6*(k1.vx+2*k2.vx+2*k3.vx+k4.vx)
+		o.VelocityY = r.start[i].vy + dt/6*(k1.vy+2*k2.vy+2*k3.vy+k4.vy)
+		o.ax, o.ay = k1.vx, k1.vy
+	}
+}
+
+func resizeStates(s []rk4State, n int) []rk4State {
+	if cap(s) < n {
+		return make([]rk4State, n)
+	}
+	return s[:n]
+}
+
+// Yoshida 4th-order coefficients (triple-jump composition of leapfrog).
+var (
+	yoshidaW1 = 1 / (2 - math.Cbrt(2))
+	yoshidaW0 = -math.Cbrt(2) / (2 - math.Cbrt(2))
+	yoshidaC  = [4]float64{yoshidaW1 / 2, (yoshidaW0 + yoshidaW1) / 2, (yoshidaW0 + yoshidaW1) / 2, yoshidaW1 / 2}
+	yoshidaD  = [3]float64{yoshidaW1, yoshidaW0, yoshidaW1}
+)
+
+// yoshidaIntegrator is the 4th-order symplectic integrator of Yoshida (1990):
+// four drifts interleaved with three kicks, one of them backwards in time.
+type yoshidaIntegrator struct {
+	accels []accel
+}
+
+func (y *yoshidaIntegrator) Step(w *World, dt float64) {
+	for s := 0; s < 4; s++ {
+		for _, o := range w.objects {
+			if o.Pinned {
+				continue
+			}
+			o.X += yoshidaC[s] * o.VelocityX * dt
+			o.Y += yoshidaC[s] * o.VelocityY * dt
+		}
+		if s == 3 {
+			break
+		}
+		y.accels = w.computeAccelerations(y.accels)
+		for i, o := range w.objects {
+			if o.Pinned {
+				continue
+			}
+			o.ax, o.ay = y.accels[i].ax, y.accels[i].ay
+			o.VelocityX += yoshidaD[s] * o.ax * dt
+			o.VelocityY += yoshidaD[s] * o.ay * dt
+		}
+	}
+}
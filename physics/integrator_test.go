@@ -0,0 +1,47 @@
+package physics
+
+import (
+	"math"
+	"testing"
+)
+
+// eccentricOrbit returns a world with a planet on an eccentric orbit around
+// a pinned star, integrated by kind.
+func eccentricOrbit(kind IntegratorKind) *World {
+	w := NewWorld(DefaultConfig())
+	w.GravityMode = GravityNewtonian
+	w.MergeOnCollision = false
+	star := w.AddObject(800, 600, 20)
+	star.Pinned = true
+	star.Mass = 50000
+	planet := w.AddObject(1000, 600, 3)
+	w.SetIntegrator(kind)
+	// 80% of circular speed
+	planet.VelocityY = 0.8 * math.Sqrt(math.Hypot(planet.ax, planet.ay)*200)
+	return w
+}
+
+// maxEnergyDrift steps w and returns the largest relative energy error seen.
+func maxEnergyDrift(w *World, steps int) float64 {
+	base := w.ComputeDiagnostics()
+	var worst float64
+	for i := 0; i < steps; i++ {
+		w.StepPhysics()
+		worst = math.Max(worst, math.Abs(w.ComputeDiagnostics().DriftFrom(base).Energy))
+	}
+	return worst
+}
+
+// Higher-order schemes must hold energy more tightly over the same orbits.
+func TestEnergyDriftOrderedByIntegrator(t *testing.T) {
+	order := []IntegratorKind{IntegratorEuler, IntegratorVerlet, IntegratorYoshida}
+	drift := make([]float64, len(order))
+	for i, kind := range order {
+		drift[i] = maxEnergyDrift(eccentricOrbit(kind), 3000)
+	}
+	for i := 1; i < len(order); i++ {
+		if drift[i] >= drift[i-1] {
+			t.Errorf("%v drifts %.3g, no less than %v's %.3g", order[i], drift[i], order[i-1], drift[i-1])
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package physics
+
+// Material is what a body is made of. It sets the density that turns the
+// body's size into mass, and how it behaves in contacts and drag.
+type Material int
+
+const (
+	MaterialRock Material = iota
+	MaterialIce
+	MaterialGas
+	MaterialStar
+	MaterialBlackHole
+	NumMaterials
+)
+
+func (m Material) String() string {
+	switch m {
+	case MaterialIce:
+		return "Ice"
+	case MaterialGas:
+		return "Gas"
+	case MaterialStar:
+		return "Star"
+	case MaterialBlackHole:
+		return "Black Hole"
+	default:
+		return "Rock"
+	}
+}
+
+// MaterialProps are the physical properties of a material.
+type MaterialProps struct {
+	// Mass per unit of radius². Rock is 1, so rock bodies keep the
+	// sandbox's original mass = radius².
+	Density float64
+	// Scales World.Restitution; a contact uses the softer body's value.
+	Restitution float64
+	// Scales World.FrictionCoeff for this body.
+	Drag float64
+}
+
+var materials = [NumMaterials]MaterialProps{
+	MaterialRock:      {Density: 1, Restitution: 1, Drag: 1},
+	MaterialIce:       {Density: 0.4, Restitution: 0.9, Drag: 1},
+	MaterialGas:       {Density: 0.15, Restitution: 0.3, Drag: 2},
+	MaterialStar:      {Density: 10, Restitution: 0.1, Drag: 0.5},
+	MaterialBlackHole: {Density: 1000, Restitution: 0, Drag: 0},
+}
+
+// Props returns the material's properties.
+func (m Material) Props() MaterialProps {
+	if m < 0 || m >= NumMaterials {
+		return materials[MaterialRock]
+	}
+	return materials[m]
+}
+
+// mergedMaterial returns what a merger of a body of material a and mass ma
+// with one of material b and mass mb is made of: a black hole swallows
+// anything, a star absorbs anything but a black hole, and otherwise the
+// heavier body decides.
+func mergedMaterial(a Material, ma float64, b Material, mb float64) Material {
+	switch {
+	case a == MaterialBlackHole || b == MaterialBlackHole:
+		return MaterialBlackHole
+	case a == MaterialStar || b == MaterialStar:
+		return MaterialStar
+	case mb > ma:
+		return b
+	default:
+		return a
+	}
+}
+
+// SetMaterial changes what o is made of and sets its mass from the new
+// density and its radius.
+func (o *Object) SetMaterial(m Material) {
+	o.Material = m
+	o.Mass = m.Props().Density * o.Radius * o.Radius
+}
+
+// pairRestitution returns the restitution for a contact between a and b.
+func (w *World) pairRestitution(a, b *Object) float64 {
+	return w.Restitution * min(a.Material.Props().Restitution, b.Material.Props().Restitution)
+}
@@ -0,0 +1,243 @@
+package physics
+
+import "math"
+
+// ObjectID identifies an object for the life of its world. IDs increase
+// monotonically and are never reused, so a stale ID simply fails to resolve.
+type ObjectID uint64
+
+// NoObject is the zero ID; it never refers to an object.
+const NoObject ObjectID = 0
+
+// Object is a body in the world. Fields may be read freely; between steps
+// they may also be written (dragging, pinning, launching).
+type Object struct {
+	ID                   ObjectID // assigned by AddObject
+	Kind                 ObjectKind
+	X, Y                 float64
+	Radius               float64
+	Mass                 float64
+	Material             Material
+	VelocityX, VelocityY float64
+	ax, ay               float64 // acceleration (stored for Verlet integration)
+	bouncedFrames        int
+	insideRoche          bool // inside a Roche limit at the last check, see disruptInsideRoche
+	Pinned               bool // fixed in place; still attracts others
+	Color                [3]byte
+
+	// Rotation
+	Angle           float64 // current angle in radians
+	AngularVelocity float64 // radians per tick
+
+	// Merge animation
+	MergeTimer  float64 // 1.0 → 0.0, drives visual effect
+	MergeRadius float64 // expanding ring radius
+	MergeFlash  float64 // 1.0 → 0.0, white-hot flash cooling
+
+	// Black holes: mass swallowed recently, decaying each tick; drives the
+	// accretion glow
+	Accretion float64
+
+	// Fixture parameters, see ObjectKind
+	FieldX, FieldY float64  // gravity zone acceleration
+	Drag           float64  // drag zone: fraction of speed lost per tick
+	Link           ObjectID // wormhole: the other mouth
+
+	// Rockets: see Engine
+	Engine Engine
+}
+
+// calculateAcceleration returns gravitational acceleration from all other
+// objects by direct summation. It is the reference the Barnes-Hut solver is
+// compared against.
+func (o *Object) calculateAcceleration(objects []*Object, law gravityLaw) (float64, float64) {
+	var ax, ay float64
+
+	for _, obj := range objects {
+		if obj == o {
+			continue
+		}
+		fx, fy := law.acceleration(obj.X-o.X, obj.Y-o.Y, obj.Mass, o.Mass)
+		ax += fx
+		ay += fy
+	}
+
+	return ax, ay
+}
+
+// UpdatePositionVerlet performs the position half of Velocity Verlet: x += v*dt + 0.5*a*dt²
+func (o *Object) UpdatePositionVerlet(dt float64) {
+	o.X += o.VelocityX*dt + 0.5*o.ax*dt*dt
+	o.Y += o.VelocityY*dt + 0.5*o.ay*dt*dt
+}
+
+// UpdateVelocityVerlet performs the velocity half: v += 0.5*(a_old + a_new)*dt
+func (o *Object) UpdateVelocityVerlet(newAX, newAY, dt float64) {
+	o.VelocityX += 0.5 * (o.ax + newAX) * dt
+	o.VelocityY += 0.5 * (o.ay + newAY) * dt
+	o.ax = newAX
+	o.ay = newAY
+}
+
+// BounceOnScreenCollision reflects the velocity off the world bounds.
+func (o *Object) BounceOnScreenCollision(cfg Config) {
+	if o.X-o.Radius < 0 && o.VelocityX < 0 || o.X+o.Radius > cfg.Width && o.VelocityX > 0 {
+		o.VelocityX = -o.VelocityX * cfg.BounceEfficiency
+	}
+	if o.Y-o.Radius < 0 && o.VelocityY < 0 || o.Y+o.Radius > cfg.Height && o.VelocityY > 0 {
+		o.VelocityY = -o.VelocityY * cfg.BounceEfficiency
+	}
+}
+
+// CollideWith checks collision with another object, separates overlap, and applies impulse.
+// Returns true if a merge should happen (caller handles removal).
+func (o *Object) CollideWith(obj *Object, restitution float64, merge bool) bool {
+	dx := obj.X - o.X
+	dy := obj.Y - o.Y
+	distSq := dx*dx + dy*dy
+	distance := math.Sqrt(distSq)
+	minDist := o.Radius + obj.Radius
+
+	if distance >= minDist {
+		return false
+	}
+	if distance < 0.001 {
+		distance = 0.001
+	}
+
+	normalX := dx / distance
+	normalY := dy / distance
+
+	// Separate overlapping objects
+	overlap := minDist - distance
+	totalMass := o.Mass + obj.Mass
+
+	if o.Pinned {
+		obj.X += normalX * overlap
+		obj.Y += normalY * overlap
+	} else if obj.Pinned {
+		o.X -= normalX * overlap
+		o.Y -= normalY * overlap
+	} else {
+		o.X -= normalX * overlap * (obj.Mass / totalMass)
+		o.Y -= normalY * overlap * (obj.Mass / totalMass)
+		obj.X += normalX * overlap * (o.Mass / totalMass)
+		obj.Y += normalY * overlap * (o.Mass / totalMass)
+	}
+
+	if merge && !o.Pinned && !obj.Pinned {
+		return true
+	}
+
+	// Impulse-based collision with restitution
+	myProj := o.VelocityX*normalX + o.VelocityY*normalY
+	objProj := obj.VelocityX*normalX + obj.VelocityY*normalY
+
+	if o.Pinned {
+		// Only obj bounces
+		obj.VelocityX += -(1 + restitution) * (objProj - myProj) * normalX
+		obj.VelocityY += -(1 + restitution) * (objProj - myProj) * normalY
+	} else if obj.Pinned {
+		// Only o bounces
+		o.VelocityX += -(1 + restitution) * (myProj - objProj) * normalX
+		o.VelocityY += -(1 + restitution) * (myProj - objProj) * normalY
+	} else {
+		impulse := (1 + restitution) * (myProj - objProj) / totalMass
+		o.VelocityX -= impulse * obj.Mass * normalX
+		o.VelocityY -= impulse * obj.Mass * normalY
+		obj.VelocityX += impulse * o.Mass * normalX
+		obj.VelocityY += impulse * o.Mass * normalY
+	}
+
+	return false
+}
+
+// UpdateRotation advances angle by angular velocity and decays merge
+// animation and accretion glow.
+func (o *Object) UpdateRotation() {
+	o.Angle += o.AngularVelocity
+	o.Accretion *= accretionDecay
+
+	if o.MergeTimer > 0 {
+		o.MergeTimer -= 0.015
+		o.MergeRadius += 5.0
+		if o.MergeTimer < 0 {
+			o.MergeTimer = 0
+		}
+	}
+	if o.MergeFlash > 0 {
+		o.MergeFlash -= 0.03
+		if o.MergeFlash < 0 {
+			o.MergeFlash = 0
+		}
+	}
+}
+
+// MergeFrom absorbs another object: conserves mass and linear and angular
+// momentum, and combines the materials as described by mergedMaterial. The
+// heavier part's engine, if either is a rocket, carries on.
+func (o *Object) MergeFrom(obj *Object) {
+	newMass := o.Mass + obj.Mass
+
+	if obj.Mass > o.Mass {
+		o.Engine = obj.Engine
+	}
+
+	// New center-of-mass velocity
+	newVX := (o.Mass*o.VelocityX + obj.Mass*obj.VelocityX) / newMass
+	newVY := (o.Mass*o.VelocityY + obj.Mass*obj.VelocityY) / newMass
+
+	// Center of mass position
+	cx := (o.Mass*o.X + obj.Mass*obj.X) / newMass
+	cy := (o.Mass*o.Y + obj.Mass*obj.Y) / newMass
+
+	// Relative positions to center of mass
+	r1x, r1y := o.X-cx, o.Y-cy
+	r2x, r2y := obj.X-cx, obj.Y-cy
+
+	// Relative velocities to center of mass velocity
+	u1x, u1y := o.VelocityX-newVX, o.VelocityY-newVY
+	u2x, u2y := obj.VelocityX-newVX, obj.VelocityY-newVY
+
+	// Orbital angular momentum (2D cross product: r × v = rx*vy - ry*vx)
+	lOrbital := o.Mass*(r1x*u1y-r1y*u1x) + obj.Mass*(r2x*u2y-r2y*u2x)
+
+	// Spin angular momentum (I = 0.5 * m * r²)
+	i1 := 0.5 * o.Mass * o.Radius * o.Radius
+	i2 := 0.5 * obj.Mass * obj.Radius * obj.Radius
+	lSpin := i1*o.AngularVelocity + i2*obj.AngularVelocity
+
+	lTotal := lOrbital + lSpin
+
+	// New radius: area-preserving within a material, while matter of
+	// another material is packed at the density of the result
+	material := mergedMaterial(o.Material, o.Mass, obj.Material, obj.Mass)
+	area := func(b *Object) float64 {
+		if b.Material == material {
+			return b.Radius * b.Radius
+		}
+		return b.Mass / material.Props().Density
+	}
+	newRadius := math.Sqrt(area(o) + area(obj))
+
+	// New moment of inertia
+	iNew := 0.5 * newMass * newRadius * newRadius
+
+	// Apply
+	o.X = cx
+	o.Y = cy
+	o.VelocityX = newVX
+	o.VelocityY = newVY
+	o.Radius = newRadius
+	o.Mass = newMass
+	o.Material = material
+
+	if iNew > 0 {
+		o.AngularVelocity = lTotal / iNew
+	}
+
+	// Trigger merge animation
+	o.MergeTimer = 1.0
+	o.MergeRadius = o.Radius
+	o.MergeFlash = 1.0
+}
@@ -0,0 +1,43 @@
+package physics
+
+import "math"
+
+// Orbit describes the two-body orbit of one object around another: the
+// conic it would follow if nothing else pulled on it.
+type Orbit struct {
+	SemiMajor    float64 // negative for an escape trajectory
+	Eccentricity float64
+	Periapsis    float64 // closest distance
+	Apoapsis     float64 // furthest distance; +Inf when not bound
+	Bound        bool
+}
+
+// OrbitAround returns the orbit of o around primary from their relative
+// position and velocity under inverse-square gravity. It is exact for
+// GravityNewtonian apart from softening, which matters only within a few
+// Softening lengths; under GravityLegacy orbits are not conics and the result
+// is only a rough guide.
+func (w *World) OrbitAround(o, primary *Object) Orbit {
+	mu := w.config.G * (primary.Mass + o.Mass)
+	rx, ry := o.X-primary.X, o.Y-primary.Y
+	vx, vy := o.VelocityX-primary.VelocityX, o.VelocityY-primary.VelocityY
+	r := math.Hypot(rx, ry)
+	if mu <= 0 || r == 0 {
+		return Orbit{Periapsis: r, Apoapsis: math.Inf(1)}
+	}
+
+	energy := (vx*vx+vy*vy)/2 - mu/r
+	h := rx*vy - ry*vx
+	e := math.Sqrt(math.Max(0, 1+2*energy*h*h/(mu*mu)))
+	orbit := Orbit{
+		SemiMajor:    -mu / (2 * energy),
+		Eccentricity: e,
+		Periapsis:    h * h / (mu * (1 + e)),
+		Apoapsis:     math.Inf(1),
+		Bound:        energy < 0,
+	}
+	if orbit.Bound {
+		orbit.Apoapsis = 2*orbit.SemiMajor - orbit.Periapsis
+	}
+	return orbit
+}
@@ -0,0 +1,91 @@
+package physics
+
+import (
+	"runtime"
+	"sync"
+)
+
+// minParallelItems is the smallest workload worth splitting across workers;
+// below it the hand-off costs more than the work.
+const minParallelItems = 64
+
+// chunksPerWorker oversplits the range so uneven per-item costs (e.g.
+// Barnes-Hut traversals in dense vs. sparse regions) still balance out.
+const chunksPerWorker = 4
+
+type poolJob struct {
+	fn     func(lo, hi int)
+	lo, hi int
+	done   *sync.WaitGroup
+}
+
+// workerPool runs index-range jobs on a fixed set of goroutines. Each index is
+// processed exactly once and results are written by index, so the outcome is
+// identical to a serial loop regardless of scheduling. Each ParallelFor call
+// waits only for its own jobs, so one pool can serve several worlds stepped
+// from different goroutines.
+type workerPool struct {
+	workers int
+	jobs    chan poolJob
+}
+
+func newWorkerPool(workers int) *workerPool {
+	if workers < 1 {
+		workers = 1
+	}
+	p := &workerPool{
+		workers: workers,
+		jobs:    make(chan poolJob, workers*chunksPerWorker),
+	}
+	for i := 0; i < workers; i++ {
+		go p.run()
+	}
+	return p
+}
+
+var (
+	sharedPool     *workerPool
+	sharedPoolOnce sync.Once
+)
+
+// defaultWorkerPool returns the pool every world shares, sized to the number
+// of usable CPU cores when first needed. Its goroutines live as long as the
+// process, so creating and dropping worlds leaks nothing.
+func defaultWorkerPool() *workerPool {
+	sharedPoolOnce.Do(func() {
+		sharedPool = newWorkerPool(runtime.GOMAXPROCS(0))
+	})
+	return sharedPool
+}
+
+func (p *workerPool) run() {
+	for job := range p.jobs {
+		job.fn(job.lo, job.hi)
+		job.done.Done()
+	}
+}
+
+// ParallelFor calls fn over disjoint sub-ranges covering [0, n) and returns
+// once all of them have finished.
+func (p *workerPool) ParallelFor(n int, fn func(lo, hi int)) {
+	if p == nil || p.workers <= 1 || n < minParallelItems {
+		fn(0, n)
+		return
+	}
+
+	chunks := p.workers * chunksPerWorker
+	if chunks > n {
+		chunks = n
+	}
+	size := (n + chunks - 1) / chunks
+	var done sync.WaitGroup
+	for lo := 0; lo < n; lo += size {
+		hi := lo + size
+		if hi > n {
+			hi = n
+		}
+		done.Add(1)
+		p.jobs <- poolJob{fn: fn, lo: lo, hi: hi, done: &done}
+	}
+	done.Wait()
+}
@@ -0,0 +1,223 @@
+package physics
+
+import "math"
+
+// GravitySolver selects how World computes pairwise gravity.
+type GravitySolver int
+
+const (
+	SolverDirect    GravitySolver = iota // exact O(n²) direct summation
+	SolverBarnesHut                      // O(n log n) quadtree approximation
+)
+
+func (s GravitySolver) String() string {
+	switch s {
+	case SolverBarnesHut:
+		return "Barnes-Hut"
+	default:
+		return "Direct"
+	}
+}
+
+const defaultBarnesHutTheta = 0.5
+const quadMaxDepth = 32 // bodies closer than the cell size at this depth share a leaf
+
+// quadNode is one square cell of the tree. Leaves hold a linked list of
+// object indices (usually just one); internal nodes have up to four children.
+type quadNode struct {
+	cx, cy, half float64 // center and half-width of the cell
+	mass         float64
+	comX, comY   float64 // center of mass
+	children     [4]int32
+	body         int32 // first object index in a leaf, -1 if empty
+	leaf         bool
+}
+
+// quadTree is a Barnes-Hut tree rebuilt every step. Node storage is reused
+// between builds so a steady-state simulation does not allocate.
+type quadTree struct {
+	objects []*Object
+	nodes   []quadNode
+	next    []int32 // next[i] chains objects that share a leaf
+
+	// Negative masses (repulsors) would make cell centres of mass
+	// meaningless, so they stay out of the tree and are summed directly.
+	negative []int32
+}
+
+// Build constructs the tree for the given objects.
+func (t *quadTree) Build(objects []*Object) {
+	t.objects = objects
+	t.nodes = t.nodes[:0]
+	t.negative = t.negative[:0]
+	if cap(t.next) < len(objects) {
+		t.next = make([]int32, len(objects))
+	}
+	t.next = t.next[:len(objects)]
+	if len(objects) == 0 {
+		return
+	}
+
+	minX, minY := objects[0].X, objects[0].Y
+	maxX, maxY := minX, minY
+	for _, o := range objects[1:] {
+		minX = math.Min(minX, o.X)
+		minY = math.Min(minY, o.Y)
+		maxX = math.Max(maxX, o.X)
+		maxY = math.Max(maxY, o.Y)
+	}
+	half := math.Max(maxX-minX, maxY-minY)/2 + 1
+	t.newNode((minX+maxX)/2, (minY+maxY)/2, half)
+
+	for i, o := range objects {
+		t.next[i] = -1
+		if o.Mass < 0 {
+			t.negative = append(t.negative, int32(i))
+			continue
+		}
+		t.insert(0, int32(i), 0)
+	}
+
+	// Aggregates were accumulated as mass-weighted sums during insertion
+	for i := range t.nodes {
+		n := &t.nodes[i]
+		if n.mass > 0 {
+			n.comX /= n.mass
+			n.comY /= n.mass
+		}
+	}
+}
+
+func (t *quadTree) newNode(cx, cy, half float64) int32 {
+	t.nodes = append(t.nodes, quadNode{
+		cx:       cx,
+		cy:       cy,
+		half:     half,
+		children: [4]int32{-1, -1, -1, -1},
+		body:     -1,
+		leaf:     true,
+	})
+	return int32(len(t.nodes) - 1)
+}
+
+// quadrant returns the child slot (0..3) of the cell that contains (x, y).
+func (n *quadNode) quadrant(x, y float64) int {
+	q := 0
+	if x >= n.cx {
+		q |= 1
+	}
+	if y >= n.cy {
+		q |= 2
+	}
+	return q
+}
+
+// contains reports whether (x, y) lies inside the cell.
+func (n *quadNode) contains(x, y float64) bool {
+	return math.Abs(x-n.cx) <= n.half && math.Abs(y-n.cy) <= n.half
+}
+
+func (t *quadTree) insert(node, i int32, depth int) {
+	// Nodes are addressed by index: appending children may move t.nodes.
+	o := t.objects[i]
+	n := &t.nodes[node]
+	n.mass += o.Mass
+	n.comX += o.Mass * o.X
+	n.comY += o.Mass * o.Y
+
+	if n.leaf {
+		if n.body < 0 {
+			n.body = i
+			return
+		}
+		if depth >= quadMaxDepth {
+			t.next[i] = n.body
+			n.body = i
+			return
+		}
+		// Split: push the resident body down, then continue with i below
+		old := n.body
+		n.body = -1
+		n.leaf = false
+		t.insertChild(node, old, depth)
+	}
+	t.insertChild(node, i, depth)
+}
+
+func (t *quadTree) insertChild(node, i int32, depth int) {
+	o := t.objects[i]
+	n := &t.nodes[node]
+	q := n.quadrant(o.X, o.Y)
+	child := n.children[q]
+	if child < 0 {
+		h := n.half / 2
+		cx, cy := n.cx-h, n.cy-h
+		if q&1 != 0 {
+			cx = n.cx + h
+		}
+		if q&2 != 0 {
+			cy = n.cy + h
+		}
+		child = t.newNode(cx, cy, h)
+		t.nodes[node].children[q] = child
+	}
+	t.insert(child, i, depth+1)
+}
+
+// Acceleration returns the softened gravitational acceleration on object i,
+// treating any cell whose width/distance ratio is below theta as a point mass.
+// Cells containing the object itself are always opened, and leaves are summed
+// exactly, so theta = 0 reproduces calculateAcceleration. The tree is only
+// read, so Acceleration may be called concurrently for different objects.
+func (t *quadTree) Acceleration(i int, theta float64, law gravityLaw) (float64, float64) {
+	if len(t.nodes) == 0 {
+		return 0, 0
+	}
+	ax, ay := t.accumulate(0, i, theta*theta, law)
+	o := t.objects[i]
+	for _, j := range t.negative {
+		if int(j) == i {
+			continue
+		}
+		obj := t.objects[j]
+		fx, fy := law.acceleration(obj.X-o.X, obj.Y-o.Y, obj.Mass, o.Mass)
+		ax += fx
+		ay += fy
+	}
+	return ax, ay
+}
+
+// accumulate sums the acceleration on object i from the subtree rooted at node.
+func (t *quadTree) accumulate(node int32, i int, thetaSq float64, law gravityLaw) (float64, float64) {
+	o := t.objects[i]
+	n := &t.nodes[node]
+
+	var ax, ay float64
+	if n.leaf {
+		for j := n.body; j >= 0; j = t.next[j] {
+			if int(j) == i {
+				continue
+			}
+			obj := t.objects[j]
+			fx, fy := law.acceleration(obj.X-o.X, obj.Y-o.Y, obj.Mass, o.Mass)
+			ax += fx
+			ay += fy
+		}
+		return ax, ay
+	}
+
+	dx := n.comX - o.X
+	dy := n.comY - o.Y
+	width := 2 * n.half
+	if !n.contains(o.X, o.Y) && width*width < thetaSq*(dx*dx+dy*dy) {
+		return law.acceleration(dx, dy, n.mass, o.Mass)
+	}
+	for _, c := range n.children {
+		if c >= 0 {
+			fx, fy := t.accumulate(c, i, thetaSq, law)
+			ax += fx
+			ay += fy
+		}
+	}
+	return ax, ay
+}
@@ -0,0 +1,44 @@
+package physics
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// clusteredWorld returns a world of n bodies scattered over a few clumps, the
+// uneven distribution Barnes-Hut has to approximate well.
+func clusteredWorld(n int) *World {
+	w := NewWorld(DefaultConfig())
+	w.GravityMode = GravityNewtonian
+	w.Solver = SolverBarnesHut
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < n; i++ {
+		cx, cy := 300+400*float64(i%3), 300+300*float64(i%2)
+		w.AddObject(cx+rng.NormFloat64()*60, cy+rng.NormFloat64()*60, 1+rng.Float64()*4)
+	}
+	return w
+}
+
+// meanSolverError averages SolverError over every object at the given theta.
+func meanSolverError(w *World, theta float64) float64 {
+	w.BarnesHutTheta = theta
+	var sum float64
+	for _, o := range w.Objects() {
+		sum += w.SolverError(o)
+	}
+	return sum / float64(len(w.Objects()))
+}
+
+func TestBarnesHutMatchesDirectSum(t *testing.T) {
+	w := clusteredWorld(500)
+	for _, tc := range []struct {
+		theta, tolerance float64
+	}{
+		{0, 1e-12},  // opens every cell: the direct sum up to rounding
+		{0.5, 0.03}, // the default
+	} {
+		if got := meanSolverError(w, tc.theta); got > tc.tolerance {
+			t.Errorf("theta %v: mean relative error %.3g, want at most %g", tc.theta, got, tc.tolerance)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package physics
+
+// Ring is a fixed-capacity FIFO that overwrites its oldest entry when full.
+type Ring[T any] struct {
+	buf   []T
+	start int
+	n     int
+}
+
+// NewRing returns an empty ring holding at most capacity entries.
+func NewRing[T any](capacity int) *Ring[T] {
+	return &Ring[T]{buf: make([]T, capacity)}
+}
+
+func (r *Ring[T]) Push(v T) {
+	if r.n < len(r.buf) {
+		r.buf[(r.start+r.n)%len(r.buf)] = v
+		r.n++
+		return
+	}
+	r.buf[r.start] = v
+	r.start = (r.start + 1) % len(r.buf)
+}
+
+// Len returns the number of stored entries.
+func (r *Ring[T]) Len() int {
+	return r.n
+}
+
+// At returns the i-th entry, oldest first.
+func (r *Ring[T]) At(i int) T {
+	return r.buf[(r.start+i)%len(r.buf)]
+}
+
+// Truncate keeps only the oldest n entries.
+func (r *Ring[T]) Truncate(n int) {
+	if n < r.n {
+		r.n = max(n, 0)
+	}
+}
+
+func (r *Ring[T]) Clear() {
+	r.start = 0
+	r.n = 0
+}
@@ -0,0 +1,125 @@
+package physics
+
+import "math"
+
+// Rocket defaults for FitEngine.
+const (
+	DefaultFuelFraction = 0.5 // share of the rocket's mass that is propellant
+	DefaultBurnTime     = 600 // ticks to empty the tank at full throttle
+	DefaultExhaustSpeed = 4.0 // pixels per tick relative to the rocket
+)
+
+// TurnRate is how fast a rocket turns at full Turn, in radians per tick.
+const TurnRate = 0.05
+
+const (
+	exhaustLife      = 0.6 // exhaust fades faster than impact debris
+	exhaustParticles = 3   // plume particles per tick at full throttle
+)
+
+// Engine is a rocket motor. A body with an engine (see Object.Rocket) turns
+// its Heading while Turn is non-zero and, while Throttle is open and Fuel
+// lasts, burns propellant and is pushed along Heading. The propellant is part
+// of the body's Mass, so the rocket lightens as it burns and gains
+// Exhaust·ln(m0/m1) of speed, as the rocket equation says. The exhaust
+// leaves as ejecta, which carry no mass: momentum diagnostics drift while a
+// rocket fires.
+type Engine struct {
+	Heading  float64 // direction of thrust, radians, measured like Atan2 on screen coordinates
+	Throttle float64 // 0..1
+	Turn     float64 // -1..1: Heading changes by Turn·TurnRate per tick
+	Fuel     float64 // propellant left
+	BurnRate float64 // propellant burnt per tick at full throttle
+	Exhaust  float64 // exhaust speed, pixels per tick
+	Used     float64 // delta-v spent so far
+
+	burnt float64 // propellant burnt this tick, for the plume
+}
+
+// Rocket reports whether o has an engine.
+func (o *Object) Rocket() bool { return o.Engine.Exhaust > 0 }
+
+// FitEngine makes o a rocket heading along its velocity (or up, at rest),
+// with a fraction fuel of its mass as propellant, DefaultExhaustSpeed and a
+// burn rate that empties the tank in DefaultBurnTime at full throttle.
+func (o *Object) FitEngine(fuel float64) {
+	heading := -math.Pi / 2
+	if o.VelocityX != 0 || o.VelocityY != 0 {
+		heading = math.Atan2(o.VelocityY, o.VelocityX)
+	}
+	o.Engine = Engine{
+		Heading:  heading,
+		Fuel:     fuel * o.Mass,
+		BurnRate: fuel * o.Mass / DefaultBurnTime,
+		Exhaust:  DefaultExhaustSpeed,
+	}
+}
+
+// Firing reports whether o is a rocket with its throttle open and fuel left.
+// A rocket whose fuel is not less than its mass never fires: burning it all
+// would leave nothing to push, and the rocket equation an infinite delta-v.
+func (o *Object) Firing() bool {
+	e := o.Engine
+	return o.Rocket() && e.Throttle > 0 && e.Fuel > 0 && e.Fuel < o.Mass && !o.Pinned
+}
+
+// DeltaV returns the change of speed o's remaining fuel can still give it.
+func (o *Object) DeltaV() float64 {
+	e := o.Engine
+	if !o.Rocket() || e.Fuel <= 0 || e.Fuel >= o.Mass {
+		return 0
+	}
+	return e.Exhaust * math.Log(o.Mass/(o.Mass-e.Fuel))
+}
+
+// fireEngines steers every rocket and applies its thrust over dt ticks. The
+// speed gained for the propellant burnt follows the rocket equation exactly,
+// however the tick is subdivided.
+func (w *World) fireEngines(dt float64) {
+	for _, o := range w.objects {
+		if !o.Rocket() {
+			continue
+		}
+		e := &o.Engine
+		e.Heading = math.Remainder(e.Heading+e.Turn*TurnRate*dt, 2*math.Pi)
+		if !o.Firing() {
+			continue
+		}
+		burn := math.Min(e.Fuel, e.Throttle*e.BurnRate*dt)
+		dv := e.Exhaust * math.Log(o.Mass/(o.Mass-burn))
+		o.VelocityX += dv * math.Cos(e.Heading)
+		o.VelocityY += dv * math.Sin(e.Heading)
+		o.Mass -= burn
+		e.Fuel -= burn
+		e.burnt += burn
+		e.Used += dv
+	}
+}
+
+// spawnExhaust throws out the plume of every rocket that fired this tick,
+// from its tail at about the exhaust speed, fanned slightly.
+func (w *World) spawnExhaust() {
+	for _, o := range w.objects {
+		e := &o.Engine
+		if e.burnt == 0 {
+			continue
+		}
+		n := 1 + int(e.burnt/e.BurnRate*exhaustParticles)
+		e.burnt = 0
+		for i := 0; i < n; i++ {
+			// Spread varies with tick and index, so a replay draws the same plume
+			spread := 0.3 * (float64((w.tick*7+i*13)%11)/10 - 0.5)
+			dx, dy := -math.Cos(o.Engine.Heading+spread), -math.Sin(o.Engine.Heading+spread)
+			s := o.Engine.Exhaust * (0.7 + 0.3*float64((w.tick+i*5)%7)/6)
+			w.ejecta = append(w.ejecta, Ejecta{
+				X:    o.X + dx*o.Radius,
+				Y:    o.Y + dy*o.Radius,
+				VX:   o.VelocityX + dx*s,
+				VY:   o.VelocityY + dy*s,
+				Life: exhaustLife,
+				Size: 1.5 + float64(i%2),
+			})
+		}
+	}
+	w.capTracers()
+}
@@ -0,0 +1,114 @@
+package physics
+
+// Swarm holds massless test particles as parallel slices, one entry per
+// particle. Test particles are accelerated by the objects but exert no
+// gravity and never collide with each other, so tens of thousands cost
+// little more than a few objects. They are added with World.AddTestParticle
+// and removed when they hit an object or drift past Config.CullDistance.
+// The slices may be read between steps but must not be resized.
+type Swarm struct {
+	X, Y   []float64
+	VX, VY []float64
+	ax, ay []float64 // acceleration (stored for Verlet integration)
+	keep   []bool    // scratch for removal
+}
+
+// Len returns the number of test particles.
+func (s *Swarm) Len() int { return len(s.X) }
+
+func (s *Swarm) clear() {
+	s.X, s.Y = s.X[:0], s.Y[:0]
+	s.VX, s.VY = s.VX[:0], s.VY[:0]
+	s.ax, s.ay = s.ax[:0], s.ay[:0]
+}
+
+// Swarm returns the world's test particles.
+func (w *World) Swarm() *Swarm { return &w.swarm }
+
+// AddTestParticle adds a massless test particle at (x, y) with velocity
+// (vx, vy).
+func (w *World) AddTestParticle(x, y, vx, vy float64) {
+	s := &w.swarm
+	ax, ay := w.AccelerationAt(x, y, TracerMass, nil)
+	s.X = append(s.X, x)
+	s.Y = append(s.Y, y)
+	s.VX = append(s.VX, vx)
+	s.VY = append(s.VY, vy)
+	s.ax = append(s.ax, ax)
+	s.ay = append(s.ay, ay)
+}
+
+// ClearSwarm removes every test particle.
+func (w *World) ClearSwarm() { w.swarm.clear() }
+
+// stepSwarm advances the test particles over dt ticks with velocity Verlet,
+// in the gravity of the objects and gravity zones. It runs after the objects
+// have been integrated, so the new accelerations come from the objects'
+// positions at the end of the substep.
+func (w *World) stepSwarm(dt float64) {
+	s := &w.swarm
+	law := w.law()
+	objects := w.objects
+	zones := w.gravityZones()
+	w.pool.ParallelFor(s.Len(), func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			s.X[i] += s.VX[i]*dt + 0.5*s.ax[i]*dt*dt
+			s.Y[i] += s.VY[i]*dt + 0.5*s.ay[i]*dt*dt
+			ax, ay := zoneAcceleration(zones, s.X[i], s.Y[i])
+			for _, o := range objects {
+				fx, fy := law.acceleration(o.X-s.X[i], o.Y-s.Y[i], o.Mass, TracerMass)
+				ax += fx
+				ay += fy
+			}
+			s.VX[i] += 0.5 * (s.ax[i] + ax) * dt
+			s.VY[i] += 0.5 * (s.ay[i] + ay) * dt
+			s.ax[i], s.ay[i] = ax, ay
+		}
+	})
+}
+
+// cullSwarm removes test particles that are inside an object or further
+// than Config.CullDistance from the world center. Survivors keep their order.
+func (w *World) cullSwarm() {
+	s := &w.swarm
+	n := s.Len()
+	if cap(s.keep) < n {
+		s.keep = make([]bool, n)
+	}
+	keep := s.keep[:n]
+	cx, cy := w.config.CenterX, w.config.CenterY
+	limit := w.config.CullDistance
+	objects := w.objects
+	w.pool.ParallelFor(n, func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			x, y := s.X[i], s.Y[i]
+			ok := (x-cx)*(x-cx)+(y-cy)*(y-cy) <= limit*limit
+			for _, o := range objects {
+				if o.passable() {
+					continue
+				}
+				dx, dy := o.X-x, o.Y-y
+				r := o.Radius
+				if dx*dx+dy*dy < r*r {
+					ok = false
+					break
+				}
+			}
+			keep[i] = ok
+		}
+	})
+
+	j := 0
+	for i := 0; i < n; i++ {
+		if !keep[i] {
+			continue
+		}
+		s.X[j], s.Y[j] = s.X[i], s.Y[i]
+		s.VX[j], s.VY[j] = s.VX[i], s.VY[i]
+		s.ax[j], s.ay[j] = s.ax[i], s.ay[i]
+		j++
+	}
+	s.X, s.Y = s.X[:j], s.Y[:j]
+	s.VX, s.VY = s.VX[:j], s.VY[:j]
+	s.ax, s.ay = s.ax[:j], s.ay[:j]
+}
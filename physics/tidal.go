@@ -0,0 +1,116 @@
+package physics
+
+import "math"
+
+const (
+	rocheCoefficient = 2.44 // fluid-body Roche limit: d = 2.44·R·(M/m)^(1/3)
+	rocheMassRatio   = 10   // a primary must be at least this much heavier
+	minDisruptMass   = 4.0  // lighter bodies hold together (rock of radius 2 and up breaks)
+)
+
+// RocheLimit returns the distance from primary inside which sat is torn
+// apart by tides, for a fluid satellite: 2.44·R·(M/m)^(1/3), with R and m
+// the satellite's radius and mass and M the primary's mass. Written in terms
+// of the satellite's size it needs no separate density. It returns 0 when
+// the pair cannot disrupt: sat is pinned, too light or a black hole, or
+// primary is not much more massive.
+func RocheLimit(sat, primary *Object) float64 {
+	if sat.Pinned || sat.Material == MaterialBlackHole ||
+		sat.Mass < minDisruptMass || primary.Mass < rocheMassRatio*sat.Mass {
+		return 0
+	}
+	return rocheCoefficient * sat.Radius * math.Cbrt(primary.Mass/sat.Mass)
+}
+
+// disruptInsideRoche breaks up every object that has crossed into the Roche
+// limit of another since the last tick. Only bodies heavy enough to disrupt
+// anything are tried as primaries, so the cost grows with the number of
+// objects times the few heavy ones. Fragments start inside the limit and must
+// leave it and come back before they break up again; otherwise a deep plunge
+// would shatter the pieces anew every tick. Each satellite is disrupted by at
+// most one primary per tick.
+func (w *World) disruptInsideRoche() {
+	var primaries []*Object
+	for _, o := range w.objects {
+		if o.Mass >= rocheMassRatio*minDisruptMass {
+			primaries = append(primaries, o)
+		}
+	}
+
+	type disruption struct{ sat, primary *Object }
+	var found []disruption
+	for _, sat := range w.objects {
+		var primary *Object
+		for _, p := range primaries {
+			if p == sat {
+				continue
+			}
+			limit := RocheLimit(sat, p)
+			dx, dy := sat.X-p.X, sat.Y-p.Y
+			if limit > 0 && dx*dx+dy*dy < limit*limit {
+				primary = p
+				break
+			}
+		}
+		if primary != nil && !sat.insideRoche {
+			found = append(found, disruption{sat, primary})
+		}
+		sat.insideRoche = primary != nil
+	}
+	for _, d := range found {
+		w.emit(Event{Kind: EventDisrupted, A: d.sat.ID, B: d.primary.ID, X: d.sat.X, Y: d.sat.Y})
+		w.disrupt(d.sat, d.primary)
+		w.SpawnEjecta(d.sat, w.ejectaSpeed(1), 9)
+		w.RemoveObject(d.sat)
+	}
+}
+
+// disrupt replaces sat, which the caller removes, with a chain of equal
+// fragments laid along its direction of motion relative to primary. The
+// chain is stretched at the satellite's orbital angular rate, so the pieces
+// spread along the orbit. Mass, momentum and angular momentum are conserved:
+// the satellite's spin turns the chain as one body. It returns the fragments.
+func (w *World) disrupt(sat, primary *Object) []*Object {
+	n := min(maxFragments, max(2, int(math.Sqrt(sat.Mass))))
+	pieceMass := sat.Mass / float64(n)
+	pieceRadius := fragmentRadius(pieceMass, sat.Material)
+	spacing := 2*pieceRadius + 1
+
+	// Tangent to the relative motion, and the orbital angular rate
+	rx, ry := sat.X-primary.X, sat.Y-primary.Y
+	vx, vy := sat.VelocityX-primary.VelocityX, sat.VelocityY-primary.VelocityY
+	tx, ty := vx, vy
+	if speed := math.Hypot(vx, vy); speed > 0 {
+		tx, ty = vx/speed, vy/speed
+	} else {
+		tx, ty = -ry/math.Hypot(rx, ry), rx/math.Hypot(rx, ry)
+	}
+	stretch := math.Abs(rx*vy-ry*vx) / (rx*rx + ry*ry)
+
+	offsets := make([]float64, n)
+	inertia := 0.0
+	for k := range offsets {
+		offsets[k] = (float64(k) - float64(n-1)/2) * spacing
+		inertia += pieceMass * (offsets[k]*offsets[k] + 0.5*pieceRadius*pieceRadius)
+	}
+	omega := 0.5 * sat.Mass * sat.Radius * sat.Radius * sat.AngularVelocity / inertia
+
+	pieces := make([]*Object, n)
+	for k, s := range offsets {
+		p := w.AddObject(sat.X+tx*s, sat.Y+ty*s, pieceRadius)
+		p.Mass = pieceMass
+		p.Material = sat.Material
+		// Along the chain: stretch; across it: the chain's rotation
+		p.VelocityX = sat.VelocityX + tx*s*stretch - ty*s*omega
+		p.VelocityY = sat.VelocityY + ty*s*stretch + tx*s*omega
+		p.AngularVelocity = omega
+		p.ax, p.ay = sat.ax, sat.ay
+		p.Color = sat.Color
+		p.MergeTimer = 1.0
+		p.MergeRadius = pieceRadius
+		p.MergeFlash = 1.0
+		p.insideRoche = true
+		pieces[k] = p
+	}
+	return pieces
+}
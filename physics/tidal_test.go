@@ -0,0 +1,23 @@
+package physics
+
+import "testing"
+
+// Fragments are laid inside the limit their parent crossed; they must not be
+// torn up again on the following ticks.
+func TestDisruptionDoesNotCascade(t *testing.T) {
+	w := NewWorld(DefaultConfig())
+	w.GravityMode = GravityNewtonian
+	w.TidalDisruption = true
+	star := w.AddObject(800, 600, 25)
+	star.Mass, star.Pinned = 40000, true
+	sat := w.AddObject(880, 600, 8) // well inside its Roche limit of about 155
+	sat.VelocityY = -w.CircularSpeed(80, star.Mass, sat.Mass)
+
+	for i := 0; i < 10; i++ {
+		w.StepPhysics()
+	}
+
+	if n := len(w.Objects()); n > 1+maxFragments {
+		t.Fatalf("%d objects after 10 ticks, want at most the star and one chain of %d", n, maxFragments)
+	}
+}
@@ -0,0 +1,96 @@
+package physics
+
+// Snapshot is a deep copy of a world's dynamic state: its objects,
+// constraints, ejecta, tick counter and ID sequence. Settings such as
+// gravity mode or integrator are not included, so a restored snapshot
+// continues under the current settings. Neither is the Swarm: copying tens
+// of thousands of test particles every few ticks would dominate the cost of
+// a timeline, so Restore leaves them where they are and they go on from
+// there under the restored objects.
+type Snapshot struct {
+	Tick        int
+	objects     []Object
+	constraints []Constraint
+	ejecta      []Ejecta
+	nextID      ObjectID
+}
+
+// Snapshot captures the world's current state.
+func (w *World) Snapshot() Snapshot {
+	s := Snapshot{
+		Tick:        w.tick,
+		objects:     make([]Object, len(w.objects)),
+		constraints: append([]Constraint(nil), w.constraints...),
+		ejecta:      append([]Ejecta(nil), w.ejecta...),
+		nextID:      w.nextID,
+	}
+	for i, o := range w.objects {
+		s.objects[i] = *o
+	}
+	return s
+}
+
+// Restore returns the world to a snapshot. Objects keep their IDs, so IDs
+// held from before the snapshot resolve again; objects added since then are
+// removed, and objects added after restoring get the IDs they got the first
+// time. Test particles are not rewound. Restoring and stepping reproduces
+// the objects' original run exactly as long as the settings are unchanged.
+func (w *World) Restore(s Snapshot) {
+	objs := make([]*Object, len(s.objects))
+	for i := range s.objects {
+		o := s.objects[i]
+		objs[i] = &o
+	}
+	// Keep the stored accelerations rather than recomputing them: collisions
+	// may have moved objects since they were evaluated, and Verlet needs the
+	// exact values to replay identically.
+	w.setObjects(objs)
+	w.SetConstraints(s.constraints)
+	w.ejecta = append(w.ejecta[:0], s.ejecta...)
+	w.tick = s.Tick
+	w.nextID = s.nextID
+	if w.TrackDiagnostics {
+		w.diagnostics = w.ComputeDiagnostics()
+	}
+}
+
+// Timeline keeps the most recent snapshots of a world, one every Interval
+// ticks, in a bounded ring so the simulation can be rewound.
+type Timeline struct {
+	Interval int
+	snaps    *Ring[Snapshot]
+}
+
+// NewTimeline returns an empty timeline holding up to capacity snapshots.
+func NewTimeline(capacity, interval int) *Timeline {
+	return &Timeline{Interval: interval, snaps: NewRing[Snapshot](capacity)}
+}
+
+// Record captures w if its tick falls on the interval.
+func (t *Timeline) Record(w *World) {
+	if w.tick%t.Interval == 0 {
+		t.Capture(w)
+	}
+}
+
+// Capture snapshots w regardless of the interval. Nothing is recorded if the
+// newest snapshot is not older than w.
+func (t *Timeline) Capture(w *World) {
+	if n := t.snaps.Len(); n > 0 && t.snaps.At(n-1).Tick >= w.tick {
+		return
+	}
+	t.snaps.Push(w.Snapshot())
+}
+
+// Len returns the number of stored snapshots.
+func (t *Timeline) Len() int { return t.snaps.Len() }
+
+// At returns the i-th snapshot, oldest first.
+func (t *Timeline) At(i int) Snapshot { return t.snaps.At(i) }
+
+// Branch discards every snapshot after index i, so recording can continue
+// from a restored point without mixing in the abandoned future.
+func (t *Timeline) Branch(i int) { t.snaps.Truncate(i + 1) }
+
+// Clear discards all snapshots.
+func (t *Timeline) Clear() { t.snaps.Clear() }
@@ -0,0 +1,30 @@
+package physics
+
+import "testing"
+
+func TestRestoreKeepsTestParticles(t *testing.T) {
+	w := NewWorld(DefaultConfig())
+	w.AddObject(800, 600, 20)
+	snap := w.Snapshot()
+	w.AddTestParticle(900, 600, 0, 0.5)
+	w.StepPhysics()
+
+	w.Restore(snap)
+	if n := w.Swarm().Len(); n != 1 {
+		t.Fatalf("%d test particles after restore, want 1", n)
+	}
+}
+
+// A run branched from a snapshot must hand out the same IDs as the original
+// run did from that point, or replayed actions would name the wrong objects.
+func TestRestoreReissuesIDs(t *testing.T) {
+	w := NewWorld(DefaultConfig())
+	w.AddObject(800, 600, 20)
+	snap := w.Snapshot()
+	first := w.AddObject(900, 600, 5).ID
+
+	w.Restore(snap)
+	if again := w.AddObject(900, 600, 5).ID; again != first {
+		t.Fatalf("object added after restore got ID %v, want %v", again, first)
+	}
+}
@@ -0,0 +1,32 @@
+package physics
+
+import "math"
+
+// Adaptive timestep tuning. A tick always advances simulated time by 1; when
+// adaptive stepping is on it is split into substeps no longer than the
+// stable timestep, so close encounters are resolved finely while the overall
+// simulation rate stays the same.
+const (
+	timestepAccelEta = 0.25     // dt ≤ η·sqrt(ε/|a|): resolve the softening scale
+	minTimestep      = 1.0 / 64 // caps the cost of a single tick
+)
+
+// stableTimestep returns the largest substep (≤ 1) that keeps every moving
+// object within the accuracy limit, based on the accelerations stored by the
+// last integrator step. Only acceleration counts: it is large exactly when a
+// body passes close to a heavy one, while a fast body in empty space needs
+// no substeps.
+func (w *World) stableTimestep() float64 {
+	dt := 1.0
+	soft := w.config.Softening
+	for _, o := range w.objects {
+		if o.Pinned {
+			continue
+		}
+		a := math.Sqrt(o.ax*o.ax + o.ay*o.ay)
+		if a > 0 {
+			dt = math.Min(dt, timestepAccelEta*math.Sqrt(soft/a))
+		}
+	}
+	return math.Max(dt, minTimestep)
+}
@@ -0,0 +1,653 @@
+package physics
+
+import (
+	"math"
+	"sort"
+)
+
+// Config holds the constants a world is created with. They are fixed for the
+// life of the world; use DefaultConfig and override fields as needed.
+type Config struct {
+	G         float64 // gravitational constant
+	Softening float64 // Plummer softening length, avoids the singularity at r = 0
+
+	// Objects further than CullDistance from (CenterX, CenterY) are removed.
+	CullDistance     float64
+	CenterX, CenterY float64
+
+	// Bounds used by BounceOnScreenCollision, from (0, 0) to (Width, Height).
+	Width, Height    float64
+	BounceEfficiency float64 // fraction of speed kept after a wall bounce
+}
+
+// DefaultConfig returns the constants the sandbox has always used.
+func DefaultConfig() Config {
+	return Config{
+		G:                0.005,
+		Softening:        10,
+		CullDistance:     5000,
+		CenterX:          800,
+		CenterY:          600,
+		Width:            1600,
+		Height:           1200,
+		BounceEfficiency: 0.5,
+	}
+}
+
+type World struct {
+	config  Config
+	objects []*Object
+	ejecta  []Ejecta
+	swarm   Swarm
+	zones   []*Object // gravity zones, gathered for each force evaluation
+
+	constraints []Constraint
+
+	// Settings; safe to change between steps
+	BounceOnScreenCollision   bool
+	BounceOnParticleCollision bool
+	MergeOnCollision          bool
+	FragmentOnCollision       bool    // shatter bodies on impacts above FragmentThreshold
+	FragmentThreshold         float64 // specific impact energy, see DefaultFragmentThreshold
+	PhysicalEjecta            bool    // ejecta feel gravity and can be captured or re-accreted
+	TidalDisruption           bool    // bodies break up inside the Roche limit of much heavier ones
+	FrictionEnabled           bool
+	FrictionCoeff             float64
+	Restitution               float64
+	GravityMode               GravityMode
+	AdaptiveTimestep          bool
+
+	integratorKind IntegratorKind
+	integrator     Integrator
+	substeps       int // substeps taken by the last StepPhysics
+
+	// Gravity solver
+	Solver         GravitySolver
+	BarnesHutTheta float64 // opening angle: larger is faster but less accurate
+	tree           quadTree
+
+	// Worker pool for force evaluation and collision broadphase
+	pool             *workerPool
+	collisionBuckets [][]int32 // per-object candidate partners, reused between steps
+
+	// Conservation diagnostics, sampled every tick while tracking is on
+	tick                int
+	TrackDiagnostics    bool
+	diagnostics         Diagnostics
+	diagnosticsBaseline Diagnostics
+	diagnosticsHistory  *Ring[Diagnostics]
+	potentialScratch    []float64
+
+	// Object IDs and event delivery
+	nextID         ObjectID
+	index          map[ObjectID]int // ID → position in objects
+	subscribers    []subscriber
+	nextSubscriber int
+	pendingEvents  []Event
+	stepping       bool // inside StepPhysics: queue events until the step ends
+	flushing       bool
+}
+
+// Ejecta is a dust particle thrown out by a merge or a fragmenting impact.
+// Ejecta have no mass and never affect objects. By default they fly straight
+// and fade quickly; with World.PhysicalEjecta they are tracers that follow
+// the gravity of the objects, fade slowly and vanish on touching one.
+// Fragments that carry mass are ordinary Objects.
+type Ejecta struct {
+	X, Y   float64
+	VX, VY float64
+	Life   float64 // 1.0 → 0.0
+	Size   float64 // initial pixel radius
+}
+
+// NewWorld returns an empty world with the sandbox's default settings.
+func NewWorld(cfg Config) *World {
+	return &World{
+		config:                    cfg,
+		objects:                   make([]*Object, 0),
+		index:                     make(map[ObjectID]int),
+		BounceOnScreenCollision:   false,
+		BounceOnParticleCollision: true,
+		MergeOnCollision:          true,
+		FragmentThreshold:         DefaultFragmentThreshold,
+		FrictionCoeff:             0.001,
+		Restitution:               0.8,
+		GravityMode:               GravityLegacy,
+		integratorKind:            IntegratorVerlet,
+		integrator:                NewIntegrator(IntegratorVerlet),
+		Solver:                    SolverDirect,
+		BarnesHutTheta:            defaultBarnesHutTheta,
+		pool:                      defaultWorkerPool(),
+		diagnosticsHistory:        NewRing[Diagnostics](diagnosticsHistoryLen),
+	}
+}
+
+// Config returns the constants the world was created with.
+func (w *World) Config() Config { return w.config }
+
+// Objects returns the live objects. The slice is owned by the world and is
+// only valid until the next call that adds or removes objects; removal may
+// reorder it.
+func (w *World) Objects() []*Object { return w.objects }
+
+// Object returns the object with the given ID, or nil if it has been removed.
+func (w *World) Object(id ObjectID) *Object {
+	if i, ok := w.index[id]; ok {
+		return w.objects[i]
+	}
+	return nil
+}
+
+// Ejecta returns the live debris particles.
+func (w *World) Ejecta() []Ejecta { return w.ejecta }
+
+// Tick returns the number of StepPhysics calls so far.
+func (w *World) Tick() int { return w.tick }
+
+// Substeps returns how many substeps the last StepPhysics took.
+func (w *World) Substeps() int { return w.substeps }
+
+// IntegratorKind returns the active integration scheme.
+func (w *World) IntegratorKind() IntegratorKind { return w.integratorKind }
+
+// Clear removes every object, ejecta particle and test particle. Settings
+// are kept.
+func (w *World) Clear() {
+	w.removeAll()
+	w.ejecta = w.ejecta[:0]
+	w.swarm.clear()
+}
+
+// Reset empties the world and restarts its tick counter and ID sequence, so
+// it behaves exactly like a newly created world with the same settings. IDs
+// handed out before the reset must not be used afterwards.
+func (w *World) Reset() {
+	w.Clear()
+	w.tick = 0
+	w.nextID = NoObject
+	w.diagnosticsHistory.Clear()
+}
+
+// ReplaceObjects swaps the world's contents for objs, e.g. to restore a
+// level, and refreshes stored accelerations. The objects keep their IDs.
+func (w *World) ReplaceObjects(objs []*Object) {
+	w.setObjects(objs)
+	w.ejecta = w.ejecta[:0]
+	w.SetIntegrator(w.integratorKind)
+}
+
+// setObjects replaces the object list, emitting EventRemoved for the old
+// objects and EventSpawned for the new ones. Stored accelerations are kept.
+func (w *World) setObjects(objs []*Object) {
+	w.removeAll()
+	for _, o := range objs {
+		if o.ID == NoObject {
+			w.nextID++
+			o.ID = w.nextID
+		}
+		w.index[o.ID] = len(w.objects)
+		w.objects = append(w.objects, o)
+		w.emit(Event{Kind: EventSpawned, A: o.ID, X: o.X, Y: o.Y})
+	}
+}
+
+// removeAll empties the object list, emitting EventRemoved for each object.
+func (w *World) removeAll() {
+	old := w.objects
+	w.objects = make([]*Object, 0, cap(old))
+	clear(w.index)
+	w.constraints = w.constraints[:0]
+	for _, o := range old {
+		w.emit(Event{Kind: EventRemoved, A: o.ID, X: o.X, Y: o.Y})
+	}
+}
+
+// AddObject adds a rock body; use SetMaterial for other materials.
+func (w *World) AddObject(x, y, radius float64) *Object {
+	w.nextID++
+	obj := &Object{
+		ID:     w.nextID,
+		X:      x,
+		Y:      y,
+		Radius: radius,
+		Mass:   MaterialRock.Props().Density * radius * radius,
+		Color:  defaultParticleColor(len(w.objects)),
+	}
+	w.index[obj.ID] = len(w.objects)
+	w.objects = append(w.objects, obj)
+	w.emit(Event{Kind: EventSpawned, A: obj.ID, X: x, Y: y})
+	return obj
+}
+
+// RemoveObject removes obj if it is still in the world. The last object is
+// moved into the freed slot, so removal is O(1) but reorders Objects.
+func (w *World) RemoveObject(obj *Object) {
+	i, ok := w.index[obj.ID]
+	if !ok || w.objects[i] != obj {
+		return
+	}
+	last := len(w.objects) - 1
+	if i != last {
+		moved := w.objects[last]
+		w.objects[i] = moved
+		w.index[moved.ID] = i
+	}
+	w.objects[last] = nil
+	w.objects = w.objects[:last]
+	delete(w.index, obj.ID)
+	w.dropConstraints(obj.ID)
+	w.emit(Event{Kind: EventRemoved, A: obj.ID, X: obj.X, Y: obj.Y})
+}
+
+// RemoveID removes the object with the given ID, if any.
+func (w *World) RemoveID(id ObjectID) {
+	if o := w.Object(id); o != nil {
+		w.RemoveObject(o)
+	}
+}
+
+func (w *World) FindObject(wx, wy, radius float64) *Object {
+	for _, o := range w.objects {
+		dx := o.X - wx
+		dy := o.Y - wy
+		dist := dx*dx + dy*dy
+		threshold := radius + o.Radius
+		if o.Kind == KindGravityZone || o.Kind == KindDragZone {
+			threshold = radius // zones are picked by their centre
+		}
+		if dist < threshold*threshold {
+			return o
+		}
+	}
+	return nil
+}
+
+// StepPhysics runs one tick using the active integrator. With adaptive
+// stepping the tick is subdivided during close encounters.
+func (w *World) StepPhysics() {
+	w.tick++
+	w.stepping = true
+	w.substeps = 0
+	remaining := 1.0
+	for remaining > 0 {
+		dt := remaining
+		if w.AdaptiveTimestep {
+			dt = math.Min(w.stableTimestep(), remaining)
+			if remaining-dt < 1e-9 {
+				dt = remaining // don't leave a rounding-error sliver for next time
+			}
+		}
+		w.substep(dt)
+		w.substeps++
+		remaining -= dt
+	}
+
+	// Screen boundary
+	if w.BounceOnScreenCollision {
+		for _, o := range w.objects {
+			if o.Pinned {
+				continue
+			}
+			o.BounceOnScreenCollision(w.config)
+		}
+	}
+
+	w.teleportThroughWormholes()
+
+	if w.TidalDisruption {
+		w.disruptInsideRoche()
+	}
+
+	// Remove objects that drifted far outside the observable area
+	w.cullDistantObjects()
+	w.cullSwarm()
+
+	// Rotation and merge animation
+	for _, o := range w.objects {
+		o.UpdateRotation()
+	}
+
+	// Update ejecta, including this tick's rocket exhaust
+	w.spawnExhaust()
+	w.updateEjecta()
+
+	if w.TrackDiagnostics {
+		w.recordDiagnostics()
+	}
+
+	w.stepping = false
+	w.flushEvents()
+}
+
+// substep integrates motion, friction and collisions over dt ticks.
+func (w *World) substep(dt float64) {
+	w.integrator.Step(w, dt)
+	w.fireEngines(dt)
+	w.solveConstraints()
+	if w.PhysicalEjecta {
+		w.stepTracers(dt)
+	}
+	w.stepSwarm(dt)
+
+	if w.FrictionEnabled {
+		// Drag per material, computed once rather than per object
+		var drag [NumMaterials]float64
+		for m := range drag {
+			drag[m] = math.Pow(1-w.FrictionCoeff*Material(m).Props().Drag, dt)
+		}
+		for _, o := range w.objects {
+			if o.Pinned {
+				continue
+			}
+			d := drag[o.Material]
+			o.VelocityX *= d
+			o.VelocityY *= d
+		}
+	}
+	w.applyDragZones(dt)
+
+	// Black holes swallow whatever crosses a horizon, whatever the collision settings
+	w.absorbIntoHorizons()
+
+	// Collisions
+	if w.BounceOnParticleCollision || w.MergeOnCollision || w.FragmentOnCollision {
+		w.handleCollisions()
+	}
+}
+
+// computeAccelerations evaluates gravity on every object at the current
+// positions using the active solver, reusing out when it is large enough.
+// Entries for pinned objects are zero.
+func (w *World) computeAccelerations(out []accel) []accel {
+	if cap(out) < len(w.objects) {
+		out = make([]accel, len(w.objects))
+	}
+	out = out[:len(w.objects)]
+	if w.Solver == SolverBarnesHut {
+		w.tree.Build(w.objects)
+	}
+	zones := w.gravityZones()
+	w.pool.ParallelFor(len(w.objects), func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			o := w.objects[i]
+			if o.Pinned {
+				out[i] = accel{}
+				continue
+			}
+			ax, ay := w.accelerationOf(i, o)
+			zx, zy := zoneAcceleration(zones, o.X, o.Y)
+			out[i] = accel{ax + zx, ay + zy}
+		}
+	})
+	w.addSpringForces(out)
+	return out
+}
+
+// SetIntegrator switches the integration scheme. Stored accelerations are
+// refreshed so Verlet's first step after a switch starts from current forces.
+func (w *World) SetIntegrator(kind IntegratorKind) {
+	w.integratorKind = kind
+	w.integrator = NewIntegrator(kind)
+	accels := w.computeAccelerations(nil)
+	for i, o := range w.objects {
+		o.ax, o.ay = accels[i].ax, accels[i].ay
+	}
+}
+
+// accelerationOf returns the acceleration on w.objects[i] using the active
+// solver. The Barnes-Hut tree must already be built for the current positions.
+func (w *World) accelerationOf(i int, o *Object) (float64, float64) {
+	if w.Solver == SolverBarnesHut {
+		return w.tree.Acceleration(i, w.BarnesHutTheta, w.law())
+	}
+	return o.calculateAcceleration(w.objects, w.law())
+}
+
+// SolverError returns the relative difference between the Barnes-Hut and
+// direct-sum accelerations on obj, for comparing the approximation against
+// the reference. It returns 0 when obj is not in the world.
+func (w *World) SolverError(obj *Object) float64 {
+	for i, o := range w.objects {
+		if o != obj {
+			continue
+		}
+		ex, ey := o.calculateAcceleration(w.objects, w.law())
+		w.tree.Build(w.objects)
+		bx, by := w.tree.Acceleration(i, w.BarnesHutTheta, w.law())
+		ref := math.Sqrt(ex*ex + ey*ey)
+		if ref == 0 {
+			return 0
+		}
+		return math.Sqrt((bx-ex)*(bx-ex)+(by-ey)*(by-ey)) / ref
+	}
+	return 0
+}
+
+// handleCollisions resolves contacts in two passes. The broadphase finds
+// overlapping pairs in parallel from a read-only view of positions; the
+// narrowphase then resolves them serially in (i, j) order, testing each pair
+// against current positions, so the result does not depend on how the
+// broadphase was split across workers. A pair the broadphase missed can only
+// come to overlap once a resolution earlier in the pass moves one of its
+// objects, so pairs involving a moved object are tested as well: the outcome
+// is that of testing every pair j > i in order. Objects merged away are
+// skipped for the rest of the pass, and objects added during it (fragments)
+// wait for the next step.
+func (w *World) handleCollisions() {
+	pairs := w.collisionCandidates()
+	n := len(pairs)
+
+	var toRemove []*Object
+	var removed map[*Object]bool // objects already merged away this step
+	var moved []int              // sorted indices of objects a resolution has moved
+
+	for i, bucket := range pairs {
+		o := w.objects[i]
+		for j := nextPartner(i, i, bucket, moved, n); j < n; j = nextPartner(i, j, bucket, moved, n) {
+			obj := w.objects[j]
+			if removed[o] || removed[obj] {
+				continue
+			}
+			// Black holes have no surface; absorbIntoHorizons handles them
+			if o.Material == MaterialBlackHole || obj.Material == MaterialBlackHole ||
+				o.passable() || obj.passable() {
+				continue
+			}
+			// Earlier pairs may already have pushed these two apart
+			dx, dy := obj.X-o.X, obj.Y-o.Y
+			dist := math.Sqrt(dx*dx + dy*dy)
+			if dist >= o.Radius+obj.Radius {
+				continue
+			}
+			moved = markMoved(moved, i)
+			moved = markMoved(moved, j)
+			speed := math.Sqrt(
+				(o.VelocityX-obj.VelocityX)*(o.VelocityX-obj.VelocityX) +
+					(o.VelocityY-obj.VelocityY)*(o.VelocityY-obj.VelocityY))
+			shatter := w.shouldFragment(o, obj)
+			shouldMerge := o.CollideWith(obj, w.pairRestitution(o, obj), w.MergeOnCollision || shatter)
+			if shatter {
+				mx := (o.X + obj.X) / 2
+				my := (o.Y + obj.Y) / 2
+				w.emit(Event{Kind: EventFragment, A: o.ID, B: obj.ID, X: mx, Y: my, Speed: speed})
+				w.fragment(o, obj)
+				w.SpawnEjecta(o, w.ejectaSpeed(speed), 9+int(speed))
+				toRemove = append(toRemove, obj)
+				if removed == nil {
+					removed = make(map[*Object]bool)
+				}
+				removed[obj] = true
+			} else if shouldMerge {
+				mx := (o.X + obj.X) / 2
+				my := (o.Y + obj.Y) / 2
+				o.MergeFrom(obj)
+				w.SpawnEjecta(o, w.ejectaSpeed(speed), 9+int(speed))
+				w.emit(Event{Kind: EventMerge, A: o.ID, B: obj.ID, X: mx, Y: my, Speed: speed})
+				toRemove = append(toRemove, obj)
+				if removed == nil {
+					removed = make(map[*Object]bool)
+				}
+				removed[obj] = true
+			} else {
+				// Contact point on o's surface along the line of centers
+				cx, cy := o.X, o.Y
+				if dist > 0 {
+					cx += dx / dist * o.Radius
+					cy += dy / dist * o.Radius
+				}
+				w.emit(Event{Kind: EventCollision, A: o.ID, B: obj.ID, X: cx, Y: cy, Speed: speed})
+			}
+		}
+	}
+
+	for _, obj := range toRemove {
+		w.RemoveObject(obj)
+	}
+}
+
+// nextPartner returns the next index after j that handleCollisions must test
+// against object i: the next broadphase candidate or moved object, or every
+// index once i itself has moved. It returns n when there is none.
+func nextPartner(i, j int, bucket []int32, moved []int, n int) int {
+	if k := sort.SearchInts(moved, i); k < len(moved) && moved[k] == i {
+		return j + 1
+	}
+	next := n
+	if k := sort.Search(len(bucket), func(k int) bool { return int(bucket[k]) > j }); k < len(bucket) {
+		next = int(bucket[k])
+	}
+	if k := sort.SearchInts(moved, j+1); k < len(moved) && moved[k] < next {
+		next = moved[k]
+	}
+	return next
+}
+
+// markMoved adds index i to the sorted set moved.
+func markMoved(moved []int, i int) []int {
+	k := sort.SearchInts(moved, i)
+	if k < len(moved) && moved[k] == i {
+		return moved
+	}
+	moved = append(moved, 0)
+	copy(moved[k+1:], moved[k:])
+	moved[k] = i
+	return moved
+}
+
+// collisionCandidates returns, for each object i, the indices j > i of objects
+// currently overlapping it. Buckets are reused between steps.
+func (w *World) collisionCandidates() [][]int32 {
+	n := len(w.objects)
+	for len(w.collisionBuckets) < n {
+		w.collisionBuckets = append(w.collisionBuckets, nil)
+	}
+	buckets := w.collisionBuckets[:n]
+
+	w.pool.ParallelFor(n, func(lo, hi int) {
+		for i := lo; i < hi; i++ {
+			o := w.objects[i]
+			bucket := buckets[i][:0]
+			for j := i + 1; j < n; j++ {
+				obj := w.objects[j]
+				dx := obj.X - o.X
+				dy := obj.Y - o.Y
+				minDist := o.Radius + obj.Radius
+				if dx*dx+dy*dy < minDist*minDist {
+					bucket = append(bucket, int32(j))
+				}
+			}
+			buckets[i] = bucket
+		}
+	})
+	return buckets
+}
+
+// SpawnEjecta throws up to 16 ejecta out of src's surface at about speed
+// relative to it.
+func (w *World) SpawnEjecta(src *Object, speed float64, count int) {
+	if count > 16 {
+		count = 16
+	}
+	r := src.Radius
+	for i := 0; i < count; i++ {
+		angle := 2 * math.Pi * float64(i) / float64(count)
+		dx, dy := math.Cos(angle), math.Sin(angle)
+		// Vary speed slightly per particle
+		s := speed * (0.5 + 0.8*float64((i*7+3)%10)/10.0)
+		w.ejecta = append(w.ejecta, Ejecta{
+			X:    src.X + dx*r,
+			Y:    src.Y + dy*r,
+			VX:   src.VelocityX + dx*s,
+			VY:   src.VelocityY + dy*s,
+			Life: 1.0,
+			Size: 2.0 + float64(i%3),
+		})
+	}
+	w.capTracers()
+}
+
+// capTracers drops the oldest tracers beyond maxTracers.
+func (w *World) capTracers() {
+	if w.PhysicalEjecta && len(w.ejecta) > maxTracers {
+		w.ejecta = append(w.ejecta[:0], w.ejecta[len(w.ejecta)-maxTracers:]...)
+	}
+}
+
+// updateEjecta ages ejecta and drops the expired ones. Tracers have already
+// been moved by the substeps; dust moves here.
+func (w *World) updateEjecta() {
+	if w.PhysicalEjecta {
+		w.updateTracers()
+		return
+	}
+	n := 0
+	for i := range w.ejecta {
+		e := &w.ejecta[i]
+		e.X += e.VX
+		e.Y += e.VY
+		e.VX *= 0.97 // drag
+		e.VY *= 0.97
+		e.Life -= 0.015
+		if e.Life > 0 {
+			w.ejecta[n] = *e
+			n++
+		}
+	}
+	w.ejecta = w.ejecta[:n]
+}
+
+// cullDistantObjects removes objects that drifted further than
+// Config.CullDistance from the world center.
+func (w *World) cullDistantObjects() {
+	cx, cy := w.config.CenterX, w.config.CenterY
+	limit := w.config.CullDistance
+	var toRemove []*Object
+	for _, o := range w.objects {
+		if o.Pinned {
+			continue
+		}
+		dx := o.X - cx
+		dy := o.Y - cy
+		if dx*dx+dy*dy > limit*limit {
+			toRemove = append(toRemove, o)
+		}
+	}
+	for _, o := range toRemove {
+		w.emit(Event{Kind: EventCulled, A: o.ID, X: o.X, Y: o.Y})
+		w.RemoveObject(o)
+	}
+}
+
+func defaultParticleColor(index int) [3]byte {
+	colors := [][3]byte{
+		{255, 255, 255}, // white
+		{100, 180, 255}, // light blue
+		{255, 130, 100}, // salmon
+		{130, 255, 130}, // light green
+		{255, 220, 100}, // yellow
+		{200, 140, 255}, // purple
+		{255, 160, 200}, // pink
+		{100, 255, 220}, // cyan
+	}
+	return colors[index%len(colors)]
+}
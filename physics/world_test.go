@@ -0,0 +1,59 @@
+package physics
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// crowdedWorld returns a world of n bodies packed tightly enough that many
+// of them touch, stepped by pool.
+func crowdedWorld(n int, pool *workerPool) *World {
+	w := NewWorld(DefaultConfig())
+	w.pool = pool
+	w.GravityMode = GravityNewtonian
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < n; i++ {
+		o := w.AddObject(400+rng.Float64()*400, 300+rng.Float64()*400, 4+rng.Float64()*6)
+		o.VelocityX, o.VelocityY = rng.Float64()-0.5, rng.Float64()-0.5
+	}
+	return w
+}
+
+func TestParallelStepMatchesSerial(t *testing.T) {
+	serial := crowdedWorld(300, newWorkerPool(1))
+	parallel := crowdedWorld(300, newWorkerPool(8))
+	for step := 0; step < 200; step++ {
+		serial.StepPhysics()
+		parallel.StepPhysics()
+	}
+
+	a, b := serial.Objects(), parallel.Objects()
+	if len(a) != len(b) {
+		t.Fatalf("serial has %d objects, parallel %d", len(a), len(b))
+	}
+	for i := range a {
+		if *a[i] != *b[i] {
+			t.Fatalf("object %d differs:\nserial   %+v\nparallel %+v", a[i].ID, *a[i], *b[i])
+		}
+	}
+}
+
+// A resolution that pushes a body into a third one the broadphase did not
+// pair it with must still be resolved in the same pass.
+func TestCollisionChainResolvedInOnePass(t *testing.T) {
+	w := NewWorld(DefaultConfig())
+	w.MergeOnCollision = false
+	anchor := w.AddObject(0, 0, 10)
+	anchor.Pinned = true
+	middle := w.AddObject(15, 0, 10) // overlaps anchor by 5
+	far := w.AddObject(36, 0, 10)    // 1 px clear of middle until it is pushed
+
+	w.handleCollisions()
+
+	if far.X == 36 {
+		t.Fatal("far was not pushed by middle")
+	}
+	if d := far.X - middle.X; d < middle.Radius+far.Radius {
+		t.Fatalf("middle and far still overlap: %v apart", d)
+	}
+}
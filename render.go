@@ -6,19 +6,31 @@ import (
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+
+	"github.com/bondar-pavel/gravity/physics"
+	"github.com/bondar-pavel/gravity/sandbox"
 )
 
 // Renderer handles all drawing operations.
 type Renderer struct {
 	pixels   []byte        // RGBA pixel buffer for screen
 	hudImage *ebiten.Image // reusable off-screen image for scaled HUD text
+	lensBuf  []byte        // copy of the region a black hole lenses
+
+	// Barnes-Hut error of the selected object, refreshed every
+	// solverErrorInterval ticks: each probe is a direct sum over the world
+	solverErr     float64
+	solverErrID   physics.ObjectID
+	solverErrTick int
 }
 
+const solverErrorInterval = 30
+
 func newRenderer() *Renderer {
 	return &Renderer{}
 }
 
-func (r *Renderer) Draw(screen *ebiten.Image, world *World, cam *Camera, input *InputState, challenge *Challenge, target *TargetPractice) {
+func (r *Renderer) Draw(screen *ebiten.Image, world *physics.World, cam *Camera, input *InputState, challenge *Challenge, target *TargetPractice, mission *MissionMode) {
 	if r.pixels == nil {
 		r.pixels = make([]byte, screenWidth*screenHeight*4)
 	}
@@ -33,25 +45,41 @@ func (r *Renderer) Draw(screen *ebiten.Image, world *World, cam *Camera, input *
 		r.drawGravityField(world, cam)
 	}
 
+	// Draw fixtures and test particles beneath the objects
+	r.drawFixtures(world, cam, input.selectedID)
+	r.drawSwarm(world, cam)
+	r.drawConstraints(world, cam)
+
 	// Draw objects
-	for _, o := range world.objects {
-		r.drawObject(o, cam, o == input.selectedObj)
+	for _, o := range world.Objects() {
+		if o.Kind == physics.KindBody && o.Material != physics.MaterialBlackHole {
+			r.drawObject(o, cam, o.ID == input.selectedID)
+		}
+		if o.Rocket() {
+			r.drawRocket(o, cam)
+		}
 	}
 
 	// Draw ejecta debris
 	r.drawEjecta(world, cam)
 
+	// Draw black holes last so they lens everything behind them
+	for _, o := range world.Objects() {
+		if o.Kind == physics.KindBody && o.Material == physics.MaterialBlackHole {
+			r.drawBlackHole(o, cam, o.ID == input.selectedID, input.showAccretion)
+		}
+	}
+
 	if challenge.active {
 		// Draw orbit zone circle
 		r.drawOrbitZone(challenge, cam)
 
 		// Draw tether line and projected trajectory while orbiting
-		if challenge.state == ChallengeOrbiting && challenge.orbiter != nil {
-			o := challenge.orbiter
-			ox, oy := cam.WorldToScreen(o.x, o.y)
+		if o := world.Object(challenge.orbiterID); challenge.state == ChallengeOrbiting && o != nil {
+			ox, oy := cam.WorldToScreen(o.X, o.Y)
 			cx, cy := cam.WorldToScreen(challenge.orbitCenter[0], challenge.orbitCenter[1])
 			r.drawDashedLine(ox, oy, cx, cy, [3]byte{60, 60, 80})
-			r.drawTrajectory(o.x, o.y, o.velocityX, o.velocityY, o.radius, world, cam)
+			r.drawTrajectory(o.X, o.Y, o.VelocityX, o.VelocityY, o.Mass, world, cam)
 		}
 
 		// Draw slingshot aiming visuals (challenge uses same slingshot)
@@ -63,28 +91,50 @@ func (r *Renderer) Draw(screen *ebiten.Image, world *World, cam *Camera, input *
 		r.drawTargetZones(target, cam)
 
 		// Draw projected trajectory while flying
-		if target.state == TargetFlying && target.projectile != nil {
-			p := target.projectile
-			r.drawTrajectory(p.x, p.y, p.velocityX, p.velocityY, p.radius, world, cam)
+		if p := world.Object(target.projectileID); target.state == TargetFlying && p != nil {
+			r.drawTrajectory(p.X, p.Y, p.VelocityX, p.VelocityY, p.Mass, world, cam)
 		}
 
 		// Draw slingshot aiming visuals
 		if input.aiming {
 			r.drawChallengeSlingshot(input, cam, world)
 		}
+	} else if mission.active {
+		// Draw the goal and where the craft is heading
+		r.drawMissionGoal(mission, world, cam)
+		if c := mission.Craft(world); c != nil {
+			r.drawTrajectory(c.X, c.Y, c.VelocityX, c.VelocityY, c.Mass, world, cam)
+		}
 	} else {
 		// Draw projected trajectories for all moving objects
 		if input.showTrajectories {
 			r.drawObjectTrajectories(world, cam)
 		}
 
+		// Draw time-series graphs
+		if input.showGraphs {
+			r.drawGraphs(input.graphHistory)
+		}
+
+		// Draw rewind scrubber
+		if input.showTimeline() {
+			r.drawTimeline(input)
+		}
+
 		// Draw ghost preview at cursor
-		if !input.aiming && !input.dragging {
+		if !input.aiming && !input.dragging && !input.linking {
 			r.drawGhostCircle(input, cam)
 		}
 
-		// Draw slingshot aiming visuals
-		if input.aiming {
+		// Draw the link being dragged between two objects
+		if input.linking {
+			r.drawLinkPreview(input, world, cam)
+		}
+
+		// Draw slingshot aiming visuals, or where a fixture will go
+		if input.aiming && input.nextKind != physics.KindBody {
+			r.drawPlacement(input, cam)
+		} else if input.aiming {
 			r.drawSlingshot(input, cam, world)
 		}
 	}
@@ -96,33 +146,35 @@ func (r *Renderer) Draw(screen *ebiten.Image, world *World, cam *Camera, input *
 		r.drawChallengeHUD(screen, challenge, input)
 	} else if target.active {
 		r.drawTargetHUD(screen, target, input)
+	} else if mission.active {
+		r.drawMissionHUD(screen, mission, world, input)
 	} else {
 		r.drawHUD(screen, world, input)
 	}
 }
 
-func (r *Renderer) drawObject(o *Object, cam *Camera, selected bool) {
-	sx, sy := cam.WorldToScreen(o.x, o.y)
-	sr := cam.WorldRadius(o.radius)
+func (r *Renderer) drawObject(o *physics.Object, cam *Camera, selected bool) {
+	sx, sy := cam.WorldToScreen(o.X, o.Y)
+	sr := cam.WorldRadius(o.Radius)
 
 	// Merge animation: shockwave rings
-	if o.mergeTimer > 0 {
-		ringR := cam.WorldRadius(int(o.mergeRadius))
-		b := byte(255 * o.mergeTimer)
+	if o.MergeTimer > 0 {
+		ringR := cam.WorldRadius(o.MergeRadius)
+		b := byte(255 * o.MergeTimer)
 		r.drawCircleOutline(sx, sy, ringR, [3]byte{b, b, b})
 		if ringR > 2 {
-			b2 := byte(180 * o.mergeTimer)
+			b2 := byte(180 * o.MergeTimer)
 			r.drawCircleOutline(sx, sy, ringR-2, [3]byte{b2, b2, b2})
 		}
 		if ringR > 4 {
-			b3 := byte(100 * o.mergeTimer)
+			b3 := byte(100 * o.MergeTimer)
 			r.drawCircleOutline(sx, sy, ringR-4, [3]byte{b3, b3, b3})
 		}
 	}
 
 	// Merge animation: glow halo (early phase)
-	if o.mergeTimer > 0.5 {
-		glowIntensity := (o.mergeTimer - 0.5) * 2 // 1.0 → 0.0
+	if o.MergeTimer > 0.5 {
+		glowIntensity := (o.MergeTimer - 0.5) * 2 // 1.0 → 0.0
 		glowR := sr + int(float64(sr)*0.6*glowIntensity)
 		gb := byte(40 * glowIntensity)
 		r.drawFilledCircle(sx, sy, glowR, [3]byte{byte(80 * glowIntensity), gb, byte(20 * glowIntensity)})
@@ -134,32 +186,32 @@ func (r *Renderer) drawObject(o *Object, cam *Camera, selected bool) {
 	}
 
 	// Draw pinned indicator (outer ring)
-	if o.pinned {
+	if o.Pinned {
 		r.drawCircleOutline(sx, sy, sr+2, [3]byte{255, 100, 100})
 	}
 
 	// Merge animation: size oscillation with damping
 	drawRadius := sr
-	if o.mergeTimer > 0 {
-		drawRadius += int(math.Sin(o.mergeTimer*4*math.Pi) * 4 * o.mergeTimer)
+	if o.MergeTimer > 0 {
+		drawRadius += int(math.Sin(o.MergeTimer*4*math.Pi) * 4 * o.MergeTimer)
 	}
 
 	// Flash color: interpolate toward white-hot during mergeFlash
-	drawColor := o.color
-	if o.mergeFlash > 0 {
-		f := o.mergeFlash
+	drawColor := o.Color
+	if o.MergeFlash > 0 {
+		f := o.MergeFlash
 		if f > 0.5 {
 			// White-hot phase
 			t := (f - 0.5) * 2 // 1.0 → 0.0
-			drawColor[0] = byte(float64(o.color[0]) + (255-float64(o.color[0]))*t)
-			drawColor[1] = byte(float64(o.color[1]) + (255-float64(o.color[1]))*t)
-			drawColor[2] = byte(float64(o.color[2]) + (255-float64(o.color[2]))*t)
+			drawColor[0] = byte(float64(o.Color[0]) + (255-float64(o.Color[0]))*t)
+			drawColor[1] = byte(float64(o.Color[1]) + (255-float64(o.Color[1]))*t)
+			drawColor[2] = byte(float64(o.Color[2]) + (255-float64(o.Color[2]))*t)
 		} else {
 			// Cooling: yellow → orange → original
 			t := f * 2 // 1.0 → 0.0
-			drawColor[0] = byte(float64(o.color[0]) + (255-float64(o.color[0]))*t)
-			drawColor[1] = byte(float64(o.color[1]) + (200-float64(o.color[1]))*t)
-			drawColor[2] = o.color[2]
+			drawColor[0] = byte(float64(o.Color[0]) + (255-float64(o.Color[0]))*t)
+			drawColor[1] = byte(float64(o.Color[1]) + (200-float64(o.Color[1]))*t)
+			drawColor[2] = o.Color[2]
 		}
 	}
 
@@ -168,9 +220,9 @@ func (r *Renderer) drawObject(o *Object, cam *Camera, selected bool) {
 
 	// Draw rotating spokes (skip for small particles)
 	if sr >= 6 {
-		spokeColor := [3]byte{o.color[0] / 2, o.color[1] / 2, o.color[2] / 2}
+		spokeColor := [3]byte{o.Color[0] / 2, o.Color[1] / 2, o.Color[2] / 2}
 		for i := 0; i < 3; i++ {
-			spokeAngle := o.angle + float64(i)*2*math.Pi/3
+			spokeAngle := o.Angle + float64(i)*2*math.Pi/3
 			cos := math.Cos(spokeAngle)
 			sin := math.Sin(spokeAngle)
 			innerR := float64(drawRadius) * 0.4
@@ -234,10 +286,10 @@ func (r *Renderer) drawCircleOutline(cx, cy float64, radius int, color [3]byte)
 	}
 }
 
-func (r *Renderer) drawEjecta(world *World, cam *Camera) {
-	for i := range world.ejecta {
-		e := &world.ejecta[i]
-		sx, sy := cam.WorldToScreen(e.x, e.y)
+func (r *Renderer) drawEjecta(world *physics.World, cam *Camera) {
+	for i := range world.Ejecta() {
+		e := &world.Ejecta()[i]
+		sx, sy := cam.WorldToScreen(e.X, e.Y)
 		ix := int(sx)
 		iy := int(sy)
 		if ix < -10 || ix > screenWidth+10 || iy < -10 || iy > screenHeight+10 {
@@ -246,25 +298,25 @@ func (r *Renderer) drawEjecta(world *World, cam *Camera) {
 
 		// Color: white → yellow → orange → dark red based on life
 		var cr, cg, cb byte
-		if e.life > 0.6 {
-			t := (e.life - 0.6) / 0.4
+		if e.Life > 0.6 {
+			t := (e.Life - 0.6) / 0.4
 			cr = byte(255)
 			cg = byte(180 + 75*t)
 			cb = byte(100 + 155*t)
-		} else if e.life > 0.3 {
-			t := (e.life - 0.3) / 0.3
+		} else if e.Life > 0.3 {
+			t := (e.Life - 0.3) / 0.3
 			cr = byte(200 + 55*t)
 			cg = byte(80 + 100*t)
 			cb = byte(t * 100)
 		} else {
-			t := e.life / 0.3
+			t := e.Life / 0.3
 			cr = byte(80 + 120*t)
 			cg = byte(20 + 60*t)
 			cb = 0
 		}
 
 		// Size shrinks over life
-		sz := int(e.size * e.life)
+		sz := int(e.Size * e.Life)
 		if sz < 1 {
 			sz = 1
 		}
@@ -275,13 +327,13 @@ func (r *Renderer) drawEjecta(world *World, cam *Camera) {
 func (r *Renderer) drawGhostCircle(input *InputState, cam *Camera) {
 	wx, wy := input.cursorWorld(cam)
 	sx, sy := cam.WorldToScreen(wx, wy)
-	sr := cam.WorldRadius(input.nextRadius)
+	sr := cam.WorldRadius(fixtureRadius(input.nextKind, float64(input.nextRadius)))
 
 	// Draw faint outline
 	r.drawCircleOutline(sx, sy, sr, [3]byte{80, 80, 80})
 }
 
-func (r *Renderer) drawSlingshot(input *InputState, cam *Camera, world *World) {
+func (r *Renderer) drawSlingshot(input *InputState, cam *Camera, world *physics.World) {
 	cx, cy := input.cursorWorld(cam)
 	startSX, startSY := cam.WorldToScreen(input.aimStartX, input.aimStartY)
 	endSX, endSY := cam.WorldToScreen(cx, cy)
@@ -290,7 +342,7 @@ func (r *Renderer) drawSlingshot(input *InputState, cam *Camera, world *World) {
 	r.drawLine(startSX, startSY, endSX, endSY, [3]byte{255, 100, 100})
 
 	// Draw ghost at launch point
-	sr := cam.WorldRadius(input.nextRadius)
+	sr := cam.WorldRadius(float64(input.nextRadius))
 	r.drawCircleOutline(startSX, startSY, sr, [3]byte{150, 150, 150})
 
 	// Draw trajectory preview
@@ -300,25 +352,15 @@ func (r *Renderer) drawSlingshot(input *InputState, cam *Camera, world *World) {
 	vx := -dx * launchScale
 	vy := -dy * launchScale
 
-	r.drawTrajectory(input.aimStartX, input.aimStartY, vx, vy, input.nextRadius, world, cam)
+	r.drawTrajectory(input.aimStartX, input.aimStartY, vx, vy, input.brushMass(), world, cam)
 }
 
-func (r *Renderer) drawTrajectory(startX, startY, vx, vy float64, radius int, world *World, cam *Camera) {
+func (r *Renderer) drawTrajectory(startX, startY, vx, vy, mass float64, world *physics.World, cam *Camera) {
 	px, py := startX, startY
 	svx, svy := vx, vy
-	mass := float64(radius * radius)
-	softSq := softeningParameter * softeningParameter
 
 	for step := 0; step < 200; step++ {
-		var fx, fy float64
-		for _, o := range world.objects {
-			dx := o.x - px
-			dy := o.y - py
-			distSq := dx*dx + dy*dy + softSq
-			sizeAdj := o.mass / mass
-			fx += gravitationalConstant * sizeAdj * dx / distSq
-			fy += gravitationalConstant * sizeAdj * dy / distSq
-		}
+		fx, fy := world.AccelerationAt(px, py, mass, nil)
 
 		svx += fx
 		svy += fy
@@ -344,32 +386,16 @@ func (r *Renderer) drawTrajectory(startX, startY, vx, vy float64, radius int, wo
 	}
 }
 
-func (r *Renderer) drawObjectTrajectories(world *World, cam *Camera) {
-	softSq := softeningParameter * softeningParameter
-
-	for _, obj := range world.objects {
-		if obj.pinned {
+func (r *Renderer) drawObjectTrajectories(world *physics.World, cam *Camera) {
+	for _, obj := range world.Objects() {
+		if obj.Pinned {
 			continue
 		}
-		px, py := obj.x, obj.y
-		svx, svy := obj.velocityX, obj.velocityY
+		px, py := obj.X, obj.Y
+		svx, svy := obj.VelocityX, obj.VelocityY
 
 		for step := 0; step < 200; step++ {
-			var fx, fy float64
-			for _, o := range world.objects {
-				if o == obj {
-					continue
-				}
-				dx := o.x - px
-				dy := o.y - py
-				distSq := dx*dx + dy*dy
-				force := gravitationalConstant * o.mass / (distSq + softSq)
-				dist := math.Sqrt(distSq)
-				if dist > 0 {
-					fx += force * dx / dist
-					fy += force * dy / dist
-				}
-			}
+			fx, fy := world.AccelerationAt(px, py, obj.Mass, obj)
 
 			svx += fx
 			svy += fy
@@ -382,9 +408,9 @@ func (r *Renderer) drawObjectTrajectories(world *World, cam *Camera) {
 				if si >= 0 && si < screenWidth && sj >= 0 && sj < screenHeight {
 					idx := (sj*screenWidth + si) * 4
 					fade := 1.0 - float64(step)/200.0
-					r.pixels[idx] = byte(float64(obj.color[0]) * fade)
-					r.pixels[idx+1] = byte(float64(obj.color[1]) * fade)
-					r.pixels[idx+2] = byte(float64(obj.color[2]) * fade)
+					r.pixels[idx] = byte(float64(obj.Color[0]) * fade)
+					r.pixels[idx+1] = byte(float64(obj.Color[1]) * fade)
+					r.pixels[idx+2] = byte(float64(obj.Color[2]) * fade)
 					r.pixels[idx+3] = 0xFF
 				}
 			}
@@ -394,24 +420,25 @@ func (r *Renderer) drawObjectTrajectories(world *World, cam *Camera) {
 
 const fieldGridSize = 8 // render every 8th pixel
 
-func (r *Renderer) drawGravityField(world *World, cam *Camera) {
-	if len(world.objects) == 0 {
+func (r *Renderer) drawGravityField(world *physics.World, cam *Camera) {
+	if len(world.Objects()) == 0 {
 		return
 	}
-	softSq := softeningParameter * softeningParameter
+	cfg := world.Config()
+	softSq := cfg.Softening * cfg.Softening
 
 	for sy := 0; sy < screenHeight; sy += fieldGridSize {
 		for sx := 0; sx < screenWidth; sx += fieldGridSize {
 			wx, wy := cam.ScreenToWorld(float64(sx+fieldGridSize/2), float64(sy+fieldGridSize/2))
 
 			var field float64
-			for _, o := range world.objects {
-				dx := o.x - wx
-				dy := o.y - wy
+			for _, o := range world.Objects() {
+				dx := o.X - wx
+				dy := o.Y - wy
 				distSq := dx*dx + dy*dy + softSq
-				field += o.mass / distSq
+				field += o.Mass / distSq
 			}
-			field *= gravitationalConstant
+			field *= cfg.G
 
 			// Log scale mapping
 			intensity := math.Log1p(field * 5000)
@@ -494,7 +521,7 @@ func (r *Renderer) drawLine(x0, y0, x1, y1 float64, color [3]byte) {
 
 const hudScale = 2.0
 
-func (r *Renderer) drawHUD(screen *ebiten.Image, world *World, input *InputState) {
+func (r *Renderer) drawHUD(screen *ebiten.Image, world *physics.World, input *InputState) {
 	// Draw HUD text to a temporary image, then scale it up
 	hudW := screenWidth / hudScale
 	hudH := screenHeight / hudScale
@@ -509,45 +536,112 @@ func (r *Renderer) drawHUD(screen *ebiten.Image, world *World, input *InputState
 	if input.paused {
 		pauseStr = "  [PAUSED]"
 	}
+	if input.recording != nil {
+		pauseStr += "  [REC]"
+	} else if input.replay != nil {
+		pauseStr += fmt.Sprintf("  [REPLAY %d/%d]", world.Tick(), input.replay.Rec.EndTick)
+	}
 	fps := ebiten.ActualFPS()
-	status := fmt.Sprintf("Particles: %d  Speed: %s%s  Brush: %d  FPS: %.0f",
-		len(world.objects), speedStr, pauseStr, input.nextRadius, fps)
+	swarmStr := ""
+	if n := world.Swarm().Len(); n > 0 {
+		swarmStr = fmt.Sprintf("  Test particles: %d", n)
+	}
+	status := fmt.Sprintf("Particles: %d%s  Speed: %s%s  Brush: %d %s  FPS: %.0f",
+		len(world.Objects()), swarmStr, speedStr, pauseStr, input.nextRadius, input.brushName(), fps)
 	ebitenutil.DebugPrintAt(r.hudImage, status, 8, 8)
 
 	// Physics modes
 	frictionStr := "OFF"
-	if world.frictionEnabled {
+	if world.FrictionEnabled {
 		frictionStr = "ON"
 	}
+	collideStr := "OFF"
+	if world.BounceOnParticleCollision {
+		collideStr = "ON"
+	}
 	mergeStr := "OFF"
-	if world.mergeOnCollision {
+	if world.MergeOnCollision {
 		mergeStr = "ON"
 	}
+	fragmentStr := "OFF"
+	if world.FragmentOnCollision {
+		fragmentStr = "ON"
+	}
+	tidalStr := "OFF"
+	if world.TidalDisruption {
+		tidalStr = "ON"
+	}
 	fieldStr := "OFF"
 	if input.showField {
 		fieldStr = "ON"
 	}
-	modes := fmt.Sprintf("Friction: %s  Merge: %s  Restitution: %.1f  Field: %s",
-		frictionStr, mergeStr, world.restitution, fieldStr)
+	solverStr := world.Solver.String()
+	if world.Solver == physics.SolverBarnesHut {
+		solverStr += fmt.Sprintf(" (theta=%.1f)", world.BarnesHutTheta)
+	}
+	modes := fmt.Sprintf("Friction: %s  Collide: %s  Merge: %s  Fragment: %s  Tidal: %s  Restitution: %.1f  Field: %s  Solver: %s",
+		frictionStr, collideStr, mergeStr, fragmentStr, tidalStr, world.Restitution, fieldStr, solverStr)
 	ebitenutil.DebugPrintAt(r.hudImage, modes, 8, 24)
+	timestepStr := "Fixed"
+	if world.AdaptiveTimestep {
+		timestepStr = fmt.Sprintf("Adaptive (%d substeps)", world.Substeps())
+	}
+	ejectaStr := "Dust"
+	if world.PhysicalEjecta {
+		ejectaStr = "Physical"
+	}
+	sim := fmt.Sprintf("Gravity: %s  Integrator: %s  Timestep: %s  Ejecta: %s",
+		world.GravityMode, world.IntegratorKind(), timestepStr, ejectaStr)
+	ebitenutil.DebugPrintAt(r.hudImage, sim, 8, 40)
 
 	// Selected object info
-	if input.selectedObj != nil {
-		o := input.selectedObj
-		vel := math.Sqrt(o.velocityX*o.velocityX + o.velocityY*o.velocityY)
+	if o := input.selected(world); o != nil {
+		vel := math.Sqrt(o.VelocityX*o.VelocityX + o.VelocityY*o.VelocityY)
 		pinnedStr := ""
-		if o.pinned {
+		if o.Pinned {
 			pinnedStr = " [PINNED]"
 		}
-		info := fmt.Sprintf("Selected: mass=%.0f vel=%.3f%s", o.mass, vel, pinnedStr)
-		ebitenutil.DebugPrintAt(r.hudImage, info, 8, 40)
+		name := o.Material.String()
+		if o.Kind != physics.KindBody {
+			name = o.Kind.String()
+		}
+		info := fmt.Sprintf("Selected: %s mass=%.0f radius=%.1f vel=%.3f%s", name, o.Mass, o.Radius, vel, pinnedStr)
+		if o.Rocket() {
+			info += fmt.Sprintf("  throttle=%.0f%% fuel=%.1f dv=%.2f", o.Engine.Throttle*100, o.Engine.Fuel, o.DeltaV())
+		}
+		if world.Solver == physics.SolverBarnesHut {
+			info += fmt.Sprintf("  BH err=%.2f%%", r.solverError(world, o)*100)
+		}
+		ebitenutil.DebugPrintAt(r.hudImage, info, 8, 56)
+	}
+
+	if input.showDiagnostics {
+		r.drawDiagnosticsPanel(world, 8, 80)
+	}
+	if input.showGraphs {
+		r.drawGraphLabels(input.graphHistory)
+	}
+	if input.showPresets {
+		r.drawPresetMenu(int(hudW), int(hudH))
+	}
+	if input.showTimeline() {
+		r.drawTimelineLabel(world, input)
+	}
+	if input.statusTimer > 0 {
+		ebitenutil.DebugPrintAt(r.hudImage, input.statusMsg, 8, int(hudH)-104)
 	}
 
 	// Controls help (bottom)
-	help1 := "[LMB] Aim  [RMB] Select  [[] []] Size  [P] Pause  [+] [-] Speed  [Scroll] Zoom  [Home] Reset cam"
-	help2 := "[Del] Remove  [Space] Pin  [F] Friction  [M] Merge  [G] Field  [V] Trajectories  [O] Orbit Challenge  [T] Target Practice"
-	ebitenutil.DebugPrintAt(r.hudImage, help1, 8, int(hudH)-36)
-	ebitenutil.DebugPrintAt(r.hudImage, help2, 8, int(hudH)-20)
+	help1 := "[LMB] Aim  [RMB] Select  [[] []] Size  [Tab] Material  [P] Pause  [+] [-] Speed  [Scroll] Zoom  [Home] Camera  [Left] [Right] Rewind"
+	help2 := "[Del] Remove  [Space] Pin  [F] Friction  [M] Merge  [X] Fragment  [K] Tidal  [E] Ejecta  [G] Field  [V] Trajectories  [H] Graphs"
+	help3 := "[B] Barnes-Hut  [,] [.] Theta  [N] Newtonian/Legacy  [I] Integrator  [A] Adaptive  [D] Diagnostics  [R] Reset drift  [S] [C] Swarm"
+	help4 := "[F5] Save  [F9] Load  [F6] Record  [F10] Replay  [L] [1-9] Presets  [O] Orbit Challenge  [T] Target Practice  [J] Glow  [Q] Fixture"
+	help5 := "[Shift/Ctrl/Alt+Drag] Spring/Rod/Rope between particles  [U] Engine  [Arrows] Fly rocket  [W] Missions"
+	ebitenutil.DebugPrintAt(r.hudImage, help1, 8, int(hudH)-84)
+	ebitenutil.DebugPrintAt(r.hudImage, help2, 8, int(hudH)-68)
+	ebitenutil.DebugPrintAt(r.hudImage, help3, 8, int(hudH)-52)
+	ebitenutil.DebugPrintAt(r.hudImage, help4, 8, int(hudH)-36)
+	ebitenutil.DebugPrintAt(r.hudImage, help5, 8, int(hudH)-20)
 
 	// Draw HUD scaled up onto the main screen
 	op := &ebiten.DrawImageOptions{}
@@ -555,6 +649,40 @@ func (r *Renderer) drawHUD(screen *ebiten.Image, world *World, input *InputState
 	screen.DrawImage(r.hudImage, op)
 }
 
+// drawDiagnosticsPanel prints conserved quantities and their drift since the
+// last reset, starting at HUD position (x, y).
+func (r *Renderer) drawDiagnosticsPanel(world *physics.World, x, y int) {
+	d := world.Diagnostics()
+	base := world.DiagnosticsBaseline()
+	drift := d.DriftFrom(base)
+	elapsed := d.Tick - base.Tick
+
+	lines := []string{
+		fmt.Sprintf("CONSERVATION  (%d ticks since reset)", elapsed),
+		fmt.Sprintf("Kinetic    %12.4g", d.Kinetic),
+		fmt.Sprintf("Potential  %12.4g", d.Potential),
+		fmt.Sprintf("Energy     %12.4g  drift %+.2e", d.Energy(), drift.Energy),
+		fmt.Sprintf("Momentum   (%.3g, %.3g)  drift %.2e", d.MomentumX, d.MomentumY, drift.Momentum),
+		fmt.Sprintf("Ang. mom.  %12.4g  drift %+.2e", d.AngularMomentum, drift.AngularMomentum),
+	}
+	for i, line := range lines {
+		ebitenutil.DebugPrintAt(r.hudImage, line, x, y+16*i)
+	}
+}
+
+// drawPresetMenu lists the built-in scenes in the middle of the HUD.
+func (r *Renderer) drawPresetMenu(hudW, hudH int) {
+	x := hudW/2 - 80
+	y := hudH/2 - 16*(len(sandbox.Presets)+2)/2
+	ebitenutil.DebugPrintAt(r.hudImage, "SCENE PRESETS", x, y)
+	for i, p := range sandbox.Presets {
+		if i >= len(presetKeys) {
+			break
+		}
+		ebitenutil.DebugPrintAt(r.hudImage, fmt.Sprintf("[%d] %s", i+1, p.Name), x, y+16*(i+2))
+	}
+}
+
 // --- Challenge rendering ---
 
 func (r *Renderer) drawOrbitZone(ch *Challenge, cam *Camera) {
@@ -615,7 +743,7 @@ func (r *Renderer) drawDashedLine(x0, y0, x1, y1 float64, color [3]byte) {
 	}
 }
 
-func (r *Renderer) drawChallengeSlingshot(input *InputState, cam *Camera, world *World) {
+func (r *Renderer) drawChallengeSlingshot(input *InputState, cam *Camera, world *physics.World) {
 	cx, cy := input.cursorWorld(cam)
 	startSX, startSY := cam.WorldToScreen(input.aimStartX, input.aimStartY)
 	endSX, endSY := cam.WorldToScreen(cx, cy)
@@ -633,7 +761,7 @@ func (r *Renderer) drawChallengeSlingshot(input *InputState, cam *Camera, world
 	launchScale := 0.05
 	vx := -dx * launchScale
 	vy := -dy * launchScale
-	r.drawTrajectory(input.aimStartX, input.aimStartY, vx, vy, 5, world, cam)
+	r.drawTrajectory(input.aimStartX, input.aimStartY, vx, vy, 5*5, world, cam)
 }
 
 func (r *Renderer) drawChallengeHUD(screen *ebiten.Image, ch *Challenge, input *InputState) {
@@ -790,6 +918,131 @@ func (r *Renderer) drawTargetHUD(screen *ebiten.Image, tp *TargetPractice, input
 	screen.DrawImage(r.hudImage, op)
 }
 
+// --- Mission rendering ---
+
+// drawMissionGoal marks the orbit to reach, or the zone around the
+// rendezvous target.
+func (r *Renderer) drawMissionGoal(m *MissionMode, world *physics.World, cam *Camera) {
+	color := [3]byte{50, 200, 80}
+	if m.CurrentMission().Objective.Kind == ObjectiveRendezvous {
+		if t := world.Object(m.targetID); t != nil {
+			sx, sy := cam.WorldToScreen(t.X, t.Y)
+			r.drawDashedCircle(sx, sy, m.CurrentMission().Objective.Distance*cam.zoom, color)
+		}
+		return
+	}
+	if a := world.Object(m.aroundID); a != nil {
+		sx, sy := cam.WorldToScreen(a.X, a.Y)
+		r.drawDashedCircle(sx, sy, m.goalRadius*cam.zoom, color)
+	}
+}
+
+// drawDashedCircle draws a dashed circle of the given screen radius.
+func (r *Renderer) drawDashedCircle(cx, cy, radius float64, color [3]byte) {
+	steps := int(radius * 4)
+	if steps < 60 {
+		steps = 60
+	}
+	dashLen := 10
+	for i := 0; i < steps; i++ {
+		if (i/dashLen)%2 != 0 {
+			continue
+		}
+		angle := 2 * math.Pi * float64(i) / float64(steps)
+		ix := int(cx + radius*math.Cos(angle))
+		iy := int(cy + radius*math.Sin(angle))
+		if ix >= 0 && ix < screenWidth && iy >= 0 && iy < screenHeight {
+			idx := (iy*screenWidth + ix) * 4
+			r.pixels[idx] = color[0]
+			r.pixels[idx+1] = color[1]
+			r.pixels[idx+2] = color[2]
+			r.pixels[idx+3] = 0xFF
+		}
+	}
+}
+
+func (r *Renderer) drawMissionHUD(screen *ebiten.Image, m *MissionMode, world *physics.World, input *InputState) {
+	hudW := screenWidth / hudScale
+	hudH := screenHeight / hudScale
+	if r.hudImage == nil {
+		r.hudImage = ebiten.NewImage(int(hudW), int(hudH))
+	}
+	r.hudImage.Clear()
+
+	mission := m.CurrentMission()
+	goal := mission.Objective
+
+	// Top-left: mission info
+	title := fmt.Sprintf("MISSION %d: %s", m.currentMission+1, mission.Name)
+	ebitenutil.DebugPrintAt(r.hudImage, title, 8, 8)
+	ebitenutil.DebugPrintAt(r.hudImage, mission.Briefing, 8, 24)
+
+	// Progress towards the goal and fuel
+	if craft := m.Craft(world); craft != nil {
+		var progress string
+		if goal.Kind == ObjectiveRendezvous {
+			progress = fmt.Sprintf("Goal: within %.0f at under %.3f", goal.Distance, goal.Speed)
+			if dist, speed, ok := m.Approach(world, craft); ok {
+				progress += fmt.Sprintf("   Now: distance %.0f  relative speed %.3f", dist, speed)
+			}
+		} else {
+			progress = fmt.Sprintf("Goal: orbit at %.0f +/- %.0f", m.goalRadius, goal.Tolerance)
+			if orbit, ok := m.CraftOrbit(world, craft); ok && orbit.Bound {
+				progress += fmt.Sprintf("   Now: periapsis %.0f  apoapsis %.0f", orbit.Periapsis, orbit.Apoapsis)
+			} else if ok {
+				progress += "   Now: escaping"
+			}
+		}
+		ebitenutil.DebugPrintAt(r.hudImage, progress, 8, 40)
+
+		fuel := fmt.Sprintf("Delta-v used: %.3f  left: %.3f  Par: %.2f  Throttle: %.0f%%",
+			craft.Engine.Used, craft.DeltaV(), mission.Par, craft.Engine.Throttle*100)
+		ebitenutil.DebugPrintAt(r.hudImage, fuel, 8, 56)
+	}
+
+	// Best result
+	if best := m.bestDeltaV[m.currentMission]; best > 0 {
+		bestStr := fmt.Sprintf("Best: %.3f %s", best, starString(m.StarRating(best)))
+		ebitenutil.DebugPrintAt(r.hudImage, bestStr, 8, 72)
+	}
+
+	// Speed info
+	speedStr := fmt.Sprintf("Speed: %.1fx", input.simSpeed)
+	pauseStr := ""
+	if input.paused {
+		pauseStr = "  [PAUSED]"
+	}
+	ebitenutil.DebugPrintAt(r.hudImage, speedStr+pauseStr, 8, 88)
+
+	// Center message when the mission is over
+	if m.state != MissionFlying {
+		var msg string
+		if m.state == MissionComplete {
+			msg = fmt.Sprintf("MISSION COMPLETE!  delta-v %.3f  %s", m.result, starString(m.StarRating(m.result)))
+			if m.newBest {
+				msg += "  ** NEW BEST! **"
+			}
+		} else {
+			msg = "MISSION FAILED: " + m.failure
+		}
+		centerX := int(hudW)/2 - len(msg)*3
+		centerY := int(hudH) / 2
+		ebitenutil.DebugPrintAt(r.hudImage, msg, centerX, centerY)
+
+		retry := "Click to retry"
+		ebitenutil.DebugPrintAt(r.hudImage, retry, int(hudW)/2-len(retry)*3, centerY+20)
+	}
+
+	// Bottom help
+	help := "[Left] [Right] Turn  [Up] [Down] Throttle  [PgUp] [PgDn] Mission  [P] Pause  [+] [-] Speed  [Scroll] Zoom  [W] [Esc] Exit"
+	ebitenutil.DebugPrintAt(r.hudImage, help, 8, int(hudH)-20)
+
+	// Scale and draw
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(hudScale, hudScale)
+	screen.DrawImage(r.hudImage, op)
+}
+
 func starString(stars int) string {
 	switch stars {
 	case 3:
@@ -812,3 +1065,15 @@ func clampInt(v, min, max int) int {
 	}
 	return v
 }
+
+// solverError returns world.SolverError for o, recomputing it only when the
+// selection changes or solverErrorInterval ticks have passed.
+func (r *Renderer) solverError(world *physics.World, o *physics.Object) float64 {
+	tick := world.Tick()
+	if o.ID != r.solverErrID || tick < r.solverErrTick || tick-r.solverErrTick >= solverErrorInterval {
+		r.solverErr = world.SolverError(o)
+		r.solverErrID = o.ID
+		r.solverErrTick = tick
+	}
+	return r.solverErr
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/bondar-pavel/gravity/physics"
+	"github.com/bondar-pavel/gravity/sandbox"
+)
+
+const recordingPath = scenesDir + "/session.replay.json"
+
+// startRecording restarts the world from its current state and begins
+// logging actions.
+func (s *InputState) startRecording(world *physics.World, cam *Camera) {
+	sf := sandbox.EncodeScene(world, cam.view())
+	if err := sandbox.ResetToScene(sf, world); err != nil {
+		s.showStatus("Record failed: " + err.Error())
+		return
+	}
+	cam.setView(sf.Camera)
+	s.resetObjectRefs()
+	s.resetTimeline()
+	s.recording = &sandbox.Recording{
+		Version: sandbox.RecordingSchemaVersion,
+		Scene:   sf,
+		Speed:   s.simSpeed,
+	}
+	s.showStatus("Recording... [F6] to stop")
+}
+
+// stopRecording finishes the session and saves it.
+func (s *InputState) stopRecording(world *physics.World) {
+	rec := s.recording
+	s.recording = nil
+	rec.EndTick = world.Tick()
+	rec.Checksum = sandbox.StateChecksum(world)
+	if err := sandbox.SaveRecording(recordingPath, rec); err != nil {
+		s.showStatus("Save recording failed: " + err.Error())
+		return
+	}
+	s.showStatus(fmt.Sprintf("Recorded %d actions over %d ticks to %s", len(rec.Actions), rec.EndTick, recordingPath))
+}
+
+// startReplay resets the world to the recording's scene and plays it back.
+func (s *InputState) startReplay(rec *sandbox.Recording, world *physics.World, cam *Camera) error {
+	if err := sandbox.ResetToScene(rec.Scene, world); err != nil {
+		return err
+	}
+	cam.setView(rec.Scene.Camera)
+	s.resetObjectRefs()
+	s.resetTimeline()
+	s.simSpeed = rec.Speed
+	s.paused = false
+	s.replay = &sandbox.Playback{Rec: rec}
+	return nil
+}
+
+// advanceReplay applies every recorded action due at the current tick. It
+// must be called before each StepPhysics and returns false, ending playback,
+// once the recording's last tick is reached.
+func (s *InputState) advanceReplay(world *physics.World) bool {
+	r := s.replay
+	for _, a := range r.Due(world.Tick()) {
+		s.perform(world, a)
+	}
+	if world.Tick() < r.Rec.EndTick {
+		return true
+	}
+
+	s.replay = nil
+	s.paused = true
+	if sum := sandbox.StateChecksum(world); sum == r.Rec.Checksum {
+		s.showStatus(fmt.Sprintf("Replay finished at tick %d: state matches recording", world.Tick()))
+	} else {
+		s.showStatus(fmt.Sprintf("Replay finished at tick %d: state DIVERGED (%s, recorded %s)", world.Tick(), sum, r.Rec.Checksum))
+	}
+	return false
+}
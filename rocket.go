@@ -0,0 +1,101 @@
+package main
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+
+	"github.com/bondar-pavel/gravity/physics"
+	"github.com/bondar-pavel/gravity/sandbox"
+)
+
+// Rocket controls: [U] fits an engine to the selected body; while a rocket is
+// selected [Left]/[Right] steer it and [Up]/[Down] open and close the
+// throttle. On a gamepad the left stick steers and the right trigger is the
+// throttle.
+const (
+	throttleStep  = 0.25 // throttle change per [Up]/[Down] press
+	stickDeadZone = 0.2
+	padSteps      = 20 // analog inputs are rounded to this many steps, so recordings stay small
+)
+
+// flying returns the selected object if it is a rocket, or nil.
+func (s *InputState) flying(world *physics.World) *physics.Object {
+	if o := s.selected(world); o != nil && o.Rocket() {
+		return o
+	}
+	return nil
+}
+
+// handleRocket fits engines and flies the selected rocket. The arrow keys
+// step through the rewind timeline otherwise, so handleTimeline leaves them
+// alone while a rocket is selected.
+func (s *InputState) handleRocket(world *physics.World) {
+	if sel := s.selected(world); sel != nil && s.justPressed(ebiten.KeyU) {
+		s.perform(world, sandbox.Action{Kind: sandbox.ActionFitEngine, ID: sel.ID})
+	}
+	if rocket := s.flying(world); rocket != nil {
+		s.steer(world, rocket)
+	}
+}
+
+// steer turns the arrow keys and gamepad into engine actions for rocket.
+func (s *InputState) steer(world *physics.World, rocket *physics.Object) {
+	throttle, turn := rocket.Engine.Throttle, 0.0
+	if s.justPressed(ebiten.KeyArrowUp) {
+		throttle = math.Min(1, throttle+throttleStep)
+	}
+	if s.justPressed(ebiten.KeyArrowDown) {
+		throttle = math.Max(0, throttle-throttleStep)
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyArrowLeft) {
+		turn--
+	}
+	if ebiten.IsKeyPressed(ebiten.KeyArrowRight) {
+		turn++
+	}
+
+	s.gamepads = ebiten.AppendGamepadIDs(s.gamepads[:0])
+	for _, id := range s.gamepads {
+		if !ebiten.IsStandardGamepadLayoutAvailable(id) {
+			continue
+		}
+		if x := ebiten.StandardGamepadAxisValue(id, ebiten.StandardGamepadAxisLeftStickHorizontal); math.Abs(x) > stickDeadZone {
+			turn = math.Round(x*padSteps) / padSteps
+		}
+		// The trigger takes over while it moves, so the keys still work with a pad attached
+		t := math.Round(ebiten.StandardGamepadButtonValue(id, ebiten.StandardGamepadButtonFrontBottomRight)*padSteps) / padSteps
+		if t != s.padThrottle {
+			s.padThrottle = t
+			throttle = t
+		}
+		break
+	}
+
+	if throttle != rocket.Engine.Throttle || turn != rocket.Engine.Turn {
+		s.perform(world, sandbox.Action{Kind: sandbox.ActionEngine, ID: rocket.ID, Value: throttle, Turn: turn})
+	}
+}
+
+// drawRocket draws a nose cone on o along its heading and, while the engine
+// fires, a flame from its tail that grows with the throttle.
+func (r *Renderer) drawRocket(o *physics.Object, cam *Camera) {
+	sx, sy := cam.WorldToScreen(o.X, o.Y)
+	sr := float64(cam.WorldRadius(o.Radius))
+	hx, hy := math.Cos(o.Engine.Heading), math.Sin(o.Engine.Heading)
+	px, py := -hy, hx
+
+	nose := [3]byte{230, 230, 240}
+	tipX, tipY := sx+hx*(sr+8), sy+hy*(sr+8)
+	r.drawLine(sx+hx*sr+px*5, sy+hy*sr+py*5, tipX, tipY, nose)
+	r.drawLine(sx+hx*sr-px*5, sy+hy*sr-py*5, tipX, tipY, nose)
+
+	if !o.Firing() {
+		return
+	}
+	l := sr + 4 + 14*o.Engine.Throttle
+	flame := [3]byte{255, 160, 40}
+	r.drawLine(sx-hx*sr+px*3, sy-hy*sr+py*3, sx-hx*l, sy-hy*l, flame)
+	r.drawLine(sx-hx*sr-px*3, sy-hy*sr-py*3, sx-hx*l, sy-hy*l, flame)
+	r.drawLine(sx-hx*sr, sy-hy*sr, sx-hx*(l+4), sy-hy*(l+4), [3]byte{255, 230, 150})
+}
@@ -0,0 +1,222 @@
+package sandbox
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/bondar-pavel/gravity/physics"
+)
+
+// ActionKind names a user action that changes the sandbox.
+type ActionKind int
+
+const (
+	ActionLaunch             ActionKind = iota // add a particle at (X, Y) with velocity (VX, VY)
+	ActionDrag                                 // move object ID to (X, Y) and stop it
+	ActionPin                                  // toggle pinning of object ID
+	ActionDelete                               // remove object ID
+	ActionSpeed                                // set simulation speed to Value
+	ActionToggleField                          // G
+	ActionToggleTrajectories                   // V
+	ActionToggleDiagnostics                    // D
+	ActionToggleGraphs                         // H
+	ActionResetDrift                           // R
+	ActionToggleMerge                          // M
+	ActionToggleFriction                       // F
+	ActionToggleGravity                        // N
+	ActionCycleIntegrator                      // I
+	ActionToggleAdaptive                       // A
+	ActionToggleSolver                         // B
+	ActionTheta                                // , and .: change Barnes-Hut theta by Value
+	ActionToggleFragment                       // X
+	ActionToggleEjecta                         // E
+	ActionScatterSwarm                         // S: add test particles around object ID
+	ActionClearSwarm                           // C
+	ActionToggleTidal                          // K
+	ActionToggleAccretion                      // J
+	ActionPlace                                // place a fixture of kind Fixture at (X, Y)
+	ActionConnect                              // link objects ID and Other with a Constraint
+	ActionFitEngine                            // U: make object ID a rocket
+	ActionEngine                               // set rocket ID's throttle to Value and its steering to Turn
+	ActionToggleCollide                        // Z
+	actionKindCount
+)
+
+var actionNames = [actionKindCount]string{
+	"launch", "drag", "pin", "delete", "speed",
+	"toggle_field", "toggle_trajectories", "toggle_diagnostics", "toggle_graphs", "reset_drift",
+	"toggle_merge", "toggle_friction", "toggle_gravity", "cycle_integrator", "toggle_adaptive",
+	"toggle_solver", "theta", "toggle_fragment", "toggle_ejecta",
+	"scatter_swarm", "clear_swarm", "toggle_tidal", "toggle_accretion",
+	"place", "connect", "fit_engine", "engine", "toggle_collide",
+}
+
+func (k ActionKind) String() string {
+	if k < 0 || k >= actionKindCount {
+		return fmt.Sprintf("ActionKind(%d)", int(k))
+	}
+	return actionNames[k]
+}
+
+// MarshalText stores kinds by name so recordings survive reordering.
+func (k ActionKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+func (k *ActionKind) UnmarshalText(text []byte) error {
+	kinds := make([]ActionKind, actionKindCount)
+	for i := range kinds {
+		kinds[i] = ActionKind(i)
+	}
+	v, err := ParseEnum(string(text), kinds...)
+	if err != nil {
+		return err
+	}
+	*k = v
+	return nil
+}
+
+// Action is one user action, stamped with the world tick it happened at.
+// Which fields are used depends on Kind.
+type Action struct {
+	Tick       int              `json:"tick"`
+	Kind       ActionKind       `json:"kind"`
+	ID         physics.ObjectID `json:"id,omitempty"`
+	Other      physics.ObjectID `json:"other,omitempty"` // connect: the second object
+	X          float64          `json:"x,omitempty"`
+	Y          float64          `json:"y,omitempty"`
+	VX         float64          `json:"vx,omitempty"`
+	VY         float64          `json:"vy,omitempty"`
+	Radius     float64          `json:"radius,omitempty"`
+	Material   string           `json:"material,omitempty"`   // launch; empty is rock
+	Fixture    string           `json:"fixture,omitempty"`    // place: the fixture kind
+	Constraint string           `json:"constraint,omitempty"` // connect: the constraint kind
+	Value      float64          `json:"value,omitempty"`
+	Turn       float64          `json:"turn,omitempty"` // engine: -1..1
+}
+
+// MaterialColors are the colors of launched particles by material; rock
+// keeps the default palette.
+var MaterialColors = [physics.NumMaterials][3]byte{
+	physics.MaterialIce:       {190, 230, 255},
+	physics.MaterialGas:       {220, 180, 130},
+	physics.MaterialStar:      {255, 230, 120},
+	physics.MaterialBlackHole: {40, 30, 60},
+}
+
+// FixtureColors are the colors of fixtures by kind.
+var FixtureColors = [physics.NumKinds][3]byte{
+	physics.KindRepulsor:    {230, 80, 200},
+	physics.KindWormhole:    {150, 100, 255},
+	physics.KindGravityZone: {60, 200, 120},
+	physics.KindDragZone:    {70, 130, 220},
+}
+
+// Apply performs the part of a that changes the world and reports whether
+// it had one. Speed changes and view toggles report false and are left to
+// the caller, so a headless replay can skip them.
+func (a Action) Apply(world *physics.World) bool {
+	switch a.Kind {
+	case ActionLaunch:
+		obj := world.AddObject(a.X, a.Y, a.Radius)
+		obj.VelocityX = a.VX
+		obj.VelocityY = a.VY
+		if m, err := ParseMaterial(a.Material); err == nil && m != physics.MaterialRock {
+			obj.SetMaterial(m)
+			obj.Color = MaterialColors[m]
+		}
+	case ActionDrag:
+		if obj := world.Object(a.ID); obj != nil {
+			obj.X = a.X
+			obj.Y = a.Y
+			obj.VelocityX = 0
+			obj.VelocityY = 0
+		}
+	case ActionPlace:
+		place(world, a)
+	case ActionConnect:
+		obj, other := world.Object(a.ID), world.Object(a.Other)
+		if kind, err := ParseConstraint(a.Constraint); err == nil && obj != nil && other != nil {
+			world.Connect(kind, obj, other)
+		}
+	case ActionFitEngine:
+		if obj := world.Object(a.ID); obj != nil && obj.Kind == physics.KindBody && !obj.Rocket() {
+			obj.FitEngine(physics.DefaultFuelFraction)
+		}
+	case ActionEngine:
+		if obj := world.Object(a.ID); obj != nil && obj.Rocket() {
+			obj.Engine.Throttle = math.Max(0, math.Min(a.Value, 1))
+			obj.Engine.Turn = math.Max(-1, math.Min(a.Turn, 1))
+		}
+	case ActionPin:
+		// Fixtures stay pinned
+		if obj := world.Object(a.ID); obj != nil && obj.Kind == physics.KindBody {
+			obj.Pinned = !obj.Pinned
+		}
+	case ActionDelete:
+		world.RemoveID(a.ID)
+	case ActionScatterSwarm:
+		if obj := world.Object(a.ID); obj != nil {
+			inner := swarmInner * obj.Radius
+			ScatterSwarm(world, obj, inner, inner+swarmWidth, swarmBatch, int64(world.Tick()))
+		}
+	case ActionClearSwarm:
+		world.ClearSwarm()
+	case ActionResetDrift:
+		world.ResetDiagnostics()
+	case ActionToggleCollide:
+		world.BounceOnParticleCollision = !world.BounceOnParticleCollision
+	case ActionToggleMerge:
+		world.MergeOnCollision = !world.MergeOnCollision
+	case ActionToggleFragment:
+		world.FragmentOnCollision = !world.FragmentOnCollision
+	case ActionToggleEjecta:
+		world.PhysicalEjecta = !world.PhysicalEjecta
+	case ActionToggleTidal:
+		world.TidalDisruption = !world.TidalDisruption
+	case ActionToggleFriction:
+		world.FrictionEnabled = !world.FrictionEnabled
+	case ActionToggleGravity:
+		if world.GravityMode == physics.GravityNewtonian {
+			world.GravityMode = physics.GravityLegacy
+		} else {
+			world.GravityMode = physics.GravityNewtonian
+		}
+	case ActionCycleIntegrator:
+		world.SetIntegrator((world.IntegratorKind() + 1) % physics.NumIntegrators)
+	case ActionToggleAdaptive:
+		world.AdaptiveTimestep = !world.AdaptiveTimestep
+	case ActionToggleSolver:
+		if world.Solver == physics.SolverBarnesHut {
+			world.Solver = physics.SolverDirect
+		} else {
+			world.Solver = physics.SolverBarnesHut
+		}
+	case ActionTheta:
+		world.BarnesHutTheta = math.Max(0, math.Min(world.BarnesHutTheta+a.Value, 1.5))
+	default:
+		return false
+	}
+	return true
+}
+
+// place performs an ActionPlace. A wormhole mouth is linked to the mouth a.ID
+// if that is still unpaired.
+func place(world *physics.World, a Action) {
+	kind, err := ParseKind(a.Fixture)
+	if err != nil || kind == physics.KindBody {
+		return
+	}
+	obj := world.AddFixture(kind, a.X, a.Y, a.Radius)
+	obj.Color = FixtureColors[kind]
+	switch kind {
+	case physics.KindGravityZone:
+		obj.FieldX, obj.FieldY = a.VX, a.VY
+	case physics.KindDragZone:
+		obj.Drag = a.Value
+	case physics.KindWormhole:
+		if other := world.Object(a.ID); other != nil && other.Kind == physics.KindWormhole && other.Link == physics.NoObject {
+			physics.LinkWormholes(obj, other)
+		}
+	}
+}
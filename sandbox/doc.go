@@ -0,0 +1,16 @@
+// Package sandbox is what the gravity sandbox stores and replays, kept apart
+// from rendering and input so the headless simulator can use it: versioned
+// scene files, the built-in presets, and session recordings made of a scene
+// plus the actions taken on it, each stamped with a world tick.
+//
+// A scene is loaded into a world with ApplyScene; the view it was saved with
+// is left to the caller's camera:
+//
+//	sf, err := sandbox.LoadScene("scenes/quicksave.json")
+//	if err == nil {
+//		err = sandbox.ApplyScene(sf, world)
+//	}
+//
+// Action.Apply performs the part of a recorded action that changes the
+// world; speed changes and view toggles are left to the game.
+package sandbox
@@ -0,0 +1,350 @@
+package sandbox
+
+import (
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	"github.com/bondar-pavel/gravity/physics"
+)
+
+// Preset is a built-in scene described as data. Bodies are placed in order,
+// so orbits may refer to any earlier body by index; rings are added last,
+// and links join bodies once all are placed.
+// Add a new scene by appending to Presets.
+type Preset struct {
+	Name         string
+	Newtonian    bool    // needs Newtonian gravity; otherwise the active mode is kept
+	Merge        bool    // mergeOnCollision
+	Collide      bool    // bounceOnParticleCollision, until Z toggles it
+	Zoom         float64 // camera zoom, 0 = 1.0
+	ZeroMomentum bool    // remove net drift of the free bodies after placement
+	Bodies       []PresetBody
+	Rings        []PresetRing
+	Swarms       []PresetSwarm
+	Links        []PresetLink
+}
+
+// PresetBody is one object. Without an Orbit it is placed at (X, Y) relative
+// to the screen center with velocity (VX, VY).
+type PresetBody struct {
+	X, Y     float64
+	VX, VY   float64
+	Radius   float64
+	Mass     float64 // 0 = density × radius², as for objects added in the sandbox
+	Material physics.Material
+	Color    [3]byte
+	Pinned   bool
+	Orbit    *PresetOrbit
+}
+
+// PresetOrbit puts a body on a circular orbit around the barycenter of
+// earlier bodies, with the speed required by the active gravity law, or on an
+// ellipse when Speed is set.
+type PresetOrbit struct {
+	Around    []int
+	Distance  float64
+	Angle     float64 // degrees, counterclockwise on screen, 0 = right
+	Mutual    bool    // two-body orbit: the body's own mass also counts
+	Clockwise bool
+	Speed     float64 // multiple of the circular speed, 0 = 1; above 1 the body starts at the periapsis of an ellipse
+}
+
+// PresetRing scatters many small bodies on circular orbits around a body,
+// optionally limited to an arc.
+type PresetRing struct {
+	Around             int
+	Inner, Outer       float64
+	AngleFrom, AngleTo float64 // degrees; both 0 = full circle
+	Count              int
+	Radius             float64
+	Mass               float64 // 0 = density × radius², as for rock bodies
+	Color              [3]byte
+	Seed               int64 // positions are pseudo-random but reproducible
+}
+
+// PresetSwarm scatters massless test particles on circular orbits around a
+// body. They trace the gravity field without adding to it, so they can be
+// far more numerous than ring bodies.
+type PresetSwarm struct {
+	Around       int
+	Inner, Outer float64
+	Count        int
+	Seed         int64
+}
+
+// PresetLink connects bodies A and B, by index, at the distance they are
+// placed at.
+type PresetLink struct {
+	A, B int
+	Kind physics.ConstraintKind
+}
+
+var Presets = []Preset{
+	{
+		// Approximate planetary positions for 2026-02-17, computed from J2000
+		// mean orbital elements: L = L0 + rate_per_day * 9545, then mod 360
+		Name:    "Solar System",
+		Merge:   true,
+		Collide: true,
+		Bodies: []PresetBody{
+			// Sun (pinned at center, mass overridden for stable planetary orbits)
+			{Radius: 30, Mass: 10000, Material: physics.MaterialStar, Color: [3]byte{255, 220, 50}, Pinned: true},
+			{Radius: 3, Color: [3]byte{180, 160, 140}, Orbit: &PresetOrbit{Around: []int{0}, Distance: 80, Angle: 73}},    // Mercury
+			{Radius: 5, Color: [3]byte{230, 200, 150}, Orbit: &PresetOrbit{Around: []int{0}, Distance: 130, Angle: 346}},  // Venus
+			{Radius: 5, Color: [3]byte{100, 150, 255}, Orbit: &PresetOrbit{Around: []int{0}, Distance: 190, Angle: 148}},  // Earth
+			{Radius: 4, Color: [3]byte{220, 100, 60}, Orbit: &PresetOrbit{Around: []int{0}, Distance: 260, Angle: 317}},   // Mars
+			{Radius: 14, Color: [3]byte{200, 170, 130}, Orbit: &PresetOrbit{Around: []int{0}, Distance: 480, Angle: 108}}, // Jupiter
+			{Radius: 11, Color: [3]byte{220, 200, 150}, Orbit: &PresetOrbit{Around: []int{0}, Distance: 720, Angle: 9}},   // Saturn
+		},
+	},
+	{
+		Name:         "Binary Star",
+		Newtonian:    true,
+		Merge:        true,
+		Collide:      true,
+		ZeroMomentum: true,
+		Bodies: []PresetBody{
+			{X: -80, Radius: 18, Mass: 5000, Material: physics.MaterialStar, Color: [3]byte{255, 200, 120}},
+			{Radius: 18, Mass: 5000, Material: physics.MaterialStar, Color: [3]byte{150, 190, 255}, Orbit: &PresetOrbit{Around: []int{0}, Distance: 160, Angle: 0, Mutual: true}},
+			// Circumbinary planet, well outside the binary's unstable zone
+			{Radius: 6, Color: [3]byte{120, 230, 140}, Orbit: &PresetOrbit{Around: []int{0, 1}, Distance: 460, Angle: 90}},
+		},
+	},
+	{
+		// Chenciner-Montgomery figure-eight choreography, scaled to L = 250 px
+		// and m = 20000: positions × L, velocities × sqrt(G m / L)
+		Name:      "Figure-Eight",
+		Newtonian: true,
+		Merge:     false,
+		Collide:   false,
+		Bodies: []PresetBody{
+			{X: 242.501, Y: -60.772, VX: 0.294853, VY: 0.273452, Radius: 8, Mass: 20000, Material: physics.MaterialStar, Color: [3]byte{255, 120, 120}},
+			{X: -242.501, Y: 60.772, VX: 0.294853, VY: 0.273452, Radius: 8, Mass: 20000, Material: physics.MaterialStar, Color: [3]byte{120, 255, 120}},
+			{X: 0, Y: 0, VX: -0.589706, VY: -0.546904, Radius: 8, Mass: 20000, Material: physics.MaterialStar, Color: [3]byte{120, 160, 255}},
+		},
+	},
+	{
+		// Trojans librate around the L4/L5 points 60° ahead of and behind
+		// the planet; the planet/star mass ratio is well below Routh's 0.0385
+		Name:      "Trojan Asteroids",
+		Newtonian: true,
+		Merge:     false,
+		Collide:   false,
+		Bodies: []PresetBody{
+			{Radius: 25, Mass: 10000, Material: physics.MaterialStar, Color: [3]byte{255, 220, 50}, Pinned: true},
+			{Radius: 10, Mass: 150, Color: [3]byte{200, 170, 130}, Orbit: &PresetOrbit{Around: []int{0}, Distance: 320, Angle: 90}},
+		},
+		Rings: []PresetRing{
+			{Around: 0, Inner: 310, Outer: 330, AngleFrom: 140, AngleTo: 160, Count: 40, Radius: 2, Mass: 0.01, Color: [3]byte{170, 170, 170}, Seed: 4},
+			{Around: 0, Inner: 310, Outer: 330, AngleFrom: 20, AngleTo: 40, Count: 40, Radius: 2, Mass: 0.01, Color: [3]byte{170, 170, 170}, Seed: 5},
+		},
+	},
+	{
+		Name:      "Ring System",
+		Newtonian: true,
+		Merge:     false,
+		Collide:   false,
+		Zoom:      1.5,
+		Bodies: []PresetBody{
+			{Radius: 40, Mass: 20000, Material: physics.MaterialGas, Color: [3]byte{220, 190, 140}, Pinned: true},
+			{Radius: 6, Mass: 40, Color: [3]byte{200, 200, 210}, Orbit: &PresetOrbit{Around: []int{0}, Distance: 300, Angle: 200}},
+		},
+		Rings: []PresetRing{
+			{Around: 0, Inner: 70, Outer: 110, Count: 250, Radius: 1, Mass: 0.01, Color: [3]byte{200, 180, 150}, Seed: 1},
+			{Around: 0, Inner: 125, Outer: 170, Count: 350, Radius: 1, Mass: 0.01, Color: [3]byte{160, 150, 130}, Seed: 2},
+		},
+	},
+	{
+		// Asteroids in mean-motion resonance with the planet (3:1 at 202,
+		// 5:2 at 228, 2:1 at 265) are pumped onto eccentric orbits and
+		// scattered, opening gaps in the belt over a few dozen planet orbits
+		Name:      "Kirkwood Gaps",
+		Newtonian: true,
+		Merge:     false,
+		Collide:   false,
+		Bodies: []PresetBody{
+			{Radius: 25, Mass: 40000, Material: physics.MaterialStar, Color: [3]byte{255, 220, 50}, Pinned: true},
+			{Radius: 10, Mass: 400, Color: [3]byte{200, 170, 130}, Orbit: &PresetOrbit{Around: []int{0}, Distance: 420, Angle: 0}},
+		},
+		Swarms: []PresetSwarm{
+			{Around: 0, Inner: 150, Outer: 340, Count: 30000, Seed: 6},
+		},
+	},
+	{
+		// Two disc galaxies on a prograde flyby draw out long tidal tails
+		// and a bridge, as in Toomre & Toomre (1972)
+		Name:         "Tidal Tails",
+		Newtonian:    true,
+		Merge:        true,
+		Collide:      true,
+		Zoom:         0.6,
+		ZeroMomentum: true,
+		Bodies: []PresetBody{
+			{X: -300, Y: 160, VX: 0.35, Radius: 10, Mass: 20000, Material: physics.MaterialStar, Color: [3]byte{255, 230, 180}},
+			{X: 300, Y: -160, VX: -0.35, Radius: 10, Mass: 20000, Material: physics.MaterialStar, Color: [3]byte{180, 210, 255}},
+		},
+		Swarms: []PresetSwarm{
+			{Around: 0, Inner: 20, Outer: 130, Count: 12000, Seed: 7},
+			{Around: 1, Inner: 20, Outer: 130, Count: 12000, Seed: 8},
+		},
+	},
+	{
+		// Pairs launched on their own circular orbits, then tied together:
+		// the rope snaps taut and slack, the rod turns its pair like a
+		// dumbbell, and the spring keeps stretching and relaxing
+		Name:      "Tethers",
+		Newtonian: true,
+		Merge:     false,
+		Collide:   true,
+		Bodies: []PresetBody{
+			{Radius: 25, Mass: 40000, Material: physics.MaterialStar, Color: [3]byte{255, 220, 50}, Pinned: true},
+			{Radius: 8, Color: [3]byte{200, 170, 110}, Orbit: &PresetOrbit{Around: []int{0}, Distance: 200, Angle: 90}},
+			{Radius: 8, Color: [3]byte{200, 170, 110}, Orbit: &PresetOrbit{Around: []int{0}, Distance: 260, Angle: 90}},
+			{Radius: 8, Color: [3]byte{120, 220, 120}, Orbit: &PresetOrbit{Around: []int{0}, Distance: 300, Angle: 210}},
+			{Radius: 8, Color: [3]byte{120, 220, 120}, Orbit: &PresetOrbit{Around: []int{0}, Distance: 330, Angle: 210}},
+			{Radius: 8, Color: [3]byte{200, 200, 210}, Orbit: &PresetOrbit{Around: []int{0}, Distance: 380, Angle: 330}},
+			{Radius: 8, Color: [3]byte{200, 200, 210}, Orbit: &PresetOrbit{Around: []int{0}, Distance: 420, Angle: 330}},
+		},
+		Links: []PresetLink{
+			{A: 1, B: 2, Kind: physics.ConstraintRope},
+			{A: 3, B: 4, Kind: physics.ConstraintSpring},
+			{A: 5, B: 6, Kind: physics.ConstraintRod},
+		},
+	},
+}
+
+// Apply replaces the world's contents with the preset; the caller resets its
+// camera to the preset's Zoom. Gravity mode is switched only for presets that
+// need Newtonian gravity; solver, integrator and fragmentation settings are
+// left as the user chose them.
+func (p Preset) Apply(world *physics.World) {
+	world.Clear()
+	if p.Newtonian {
+		world.GravityMode = physics.GravityNewtonian
+	}
+	world.MergeOnCollision = p.Merge
+	world.BounceOnParticleCollision = p.Collide
+
+	cx, cy := world.Config().CenterX, world.Config().CenterY
+	for _, b := range p.Bodies {
+		obj := world.AddObject(cx+b.X, cy+b.Y, b.Radius)
+		obj.SetMaterial(b.Material)
+		if b.Mass > 0 {
+			obj.Mass = b.Mass
+		}
+		obj.Pinned = b.Pinned
+		if b.Color != ([3]byte{}) {
+			obj.Color = b.Color
+		}
+		obj.VelocityX, obj.VelocityY = b.VX, b.VY
+		if b.Orbit != nil {
+			PlaceOnOrbit(world, obj, *b.Orbit)
+		}
+	}
+
+	for _, ring := range p.Rings {
+		rng := rand.New(rand.NewSource(ring.Seed))
+		from, to := ring.AngleFrom, ring.AngleTo
+		if from == 0 && to == 0 {
+			to = 360
+		}
+		for i := 0; i < ring.Count; i++ {
+			obj := world.AddObject(cx, cy, ring.Radius)
+			if ring.Mass > 0 {
+				obj.Mass = ring.Mass
+			}
+			obj.Color = ring.Color
+			PlaceOnOrbit(world, obj, PresetOrbit{
+				Around:   []int{ring.Around},
+				Distance: ring.Inner + (ring.Outer-ring.Inner)*rng.Float64(),
+				Angle:    from + (to-from)*rng.Float64(),
+			})
+		}
+	}
+
+	if p.ZeroMomentum {
+		var px, py, m float64
+		for _, o := range world.Objects() {
+			if o.Pinned {
+				continue
+			}
+			px += o.Mass * o.VelocityX
+			py += o.Mass * o.VelocityY
+			m += o.Mass
+		}
+		if m > 0 {
+			for _, o := range world.Objects() {
+				if o.Pinned {
+					continue
+				}
+				o.VelocityX -= px / m
+				o.VelocityY -= py / m
+			}
+		}
+	}
+
+	for _, l := range p.Links {
+		world.Connect(l.Kind, world.Objects()[l.A], world.Objects()[l.B])
+	}
+
+	// Test particles last, so they follow their hosts' final velocities
+	for _, sw := range p.Swarms {
+		ScatterSwarm(world, world.Objects()[sw.Around], sw.Inner, sw.Outer, sw.Count, sw.Seed)
+	}
+
+	world.SetIntegrator(world.IntegratorKind())
+	if world.TrackDiagnostics {
+		world.ResetDiagnostics()
+	}
+}
+
+// PlaceOnOrbit sets obj's position and velocity for the orbit.
+func PlaceOnOrbit(world *physics.World, obj *physics.Object, orbit PresetOrbit) {
+	var m, x, y, vx, vy float64
+	for _, i := range orbit.Around {
+		c := world.Objects()[i]
+		m += c.Mass
+		x += c.Mass * c.X
+		y += c.Mass * c.Y
+		vx += c.Mass * c.VelocityX
+		vy += c.Mass * c.VelocityY
+	}
+	x, y, vx, vy = x/m, y/m, vx/m, vy/m
+
+	attractor := m
+	if orbit.Mutual {
+		attractor += obj.Mass
+	}
+	v := world.CircularSpeed(orbit.Distance, attractor, obj.Mass)
+	if orbit.Speed > 0 {
+		v *= orbit.Speed
+	}
+	if orbit.Clockwise {
+		v = -v
+	}
+
+	// Counterclockwise orbit (screen Y-down): tangent = (-sin θ, -cos θ)
+	rad := orbit.Angle * math.Pi / 180
+	obj.X = x + orbit.Distance*math.Cos(rad)
+	obj.Y = y - orbit.Distance*math.Sin(rad)
+	obj.VelocityX = vx - v*math.Sin(rad)
+	obj.VelocityY = vy - v*math.Cos(rad)
+}
+
+// FindPreset looks a preset up by 1-based number or case-insensitive name.
+func FindPreset(name string) (Preset, bool) {
+	if n, err := strconv.Atoi(name); err == nil {
+		if n >= 1 && n <= len(Presets) {
+			return Presets[n-1], true
+		}
+		return Preset{}, false
+	}
+	for _, p := range Presets {
+		if strings.EqualFold(p.Name, name) {
+			return p, true
+		}
+	}
+	return Preset{}, false
+}
@@ -0,0 +1,124 @@
+package sandbox
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"os"
+	"path/filepath"
+
+	"github.com/bondar-pavel/gravity/physics"
+)
+
+// RecordingSchemaVersion is written to every saved recording.
+const RecordingSchemaVersion = 1
+
+// Recording is a session: the scene it started from and every action taken,
+// stamped with the world tick. Replaying the actions on a world reset to the
+// scene reproduces the session tick for tick.
+type Recording struct {
+	Version  int      `json:"version"`
+	Scene    Scene    `json:"scene"`
+	Speed    float64  `json:"speed"`    // simulation speed when recording started
+	EndTick  int      `json:"end_tick"` // tick recording stopped at
+	Checksum string   `json:"checksum"` // StateChecksum at EndTick
+	Actions  []Action `json:"actions"`
+}
+
+// Playback steps through a recording's actions in order.
+type Playback struct {
+	Rec  *Recording
+	next int // index of the next action to apply
+}
+
+// Due returns the actions due by tick that have not been returned yet. They
+// must be applied before the world steps past tick.
+func (p *Playback) Due(tick int) []Action {
+	start := p.next
+	for p.next < len(p.Rec.Actions) && p.Rec.Actions[p.next].Tick <= tick {
+		p.next++
+	}
+	return p.Rec.Actions[start:p.next]
+}
+
+// StateChecksum hashes everything about the objects and constraints that
+// affects how the simulation continues, to check that a replay matched the
+// recording.
+func StateChecksum(world *physics.World) string {
+	h := fnv.New64a()
+	var buf [8]byte
+	put := func(v uint64) {
+		binary.LittleEndian.PutUint64(buf[:], v)
+		h.Write(buf[:])
+	}
+	put(uint64(world.Tick()))
+	for _, o := range world.Objects() {
+		put(uint64(o.ID))
+		put(math.Float64bits(o.X))
+		put(math.Float64bits(o.Y))
+		put(math.Float64bits(o.VelocityX))
+		put(math.Float64bits(o.VelocityY))
+		put(math.Float64bits(o.Mass))
+		put(math.Float64bits(o.Radius))
+		put(uint64(o.Material))
+		put(math.Float64bits(o.AngularVelocity))
+		put(uint64(o.Kind))
+		put(math.Float64bits(o.FieldX))
+		put(math.Float64bits(o.FieldY))
+		put(math.Float64bits(o.Drag))
+		put(uint64(o.Link))
+		put(math.Float64bits(o.Engine.Heading))
+		put(math.Float64bits(o.Engine.Throttle))
+		put(math.Float64bits(o.Engine.Turn))
+		put(math.Float64bits(o.Engine.Fuel))
+	}
+	for _, c := range world.Constraints() {
+		put(uint64(c.Kind))
+		put(uint64(c.A))
+		put(uint64(c.B))
+		put(math.Float64bits(c.Length))
+		put(math.Float64bits(c.Stiffness))
+		put(math.Float64bits(c.Damping))
+	}
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+// ResetToScene empties the world and loads sf into it, so recording and
+// playback both start from a world indistinguishable from a fresh one.
+func ResetToScene(sf Scene, world *physics.World) error {
+	world.Reset()
+	return ApplyScene(sf, world)
+}
+
+// SaveRecording writes rec to path, creating its directory.
+func SaveRecording(path string, rec *Recording) error {
+	data, err := json.MarshalIndent(rec, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// LoadRecording reads and validates a recording.
+func LoadRecording(path string) (*Recording, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var rec Recording
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if rec.Version != RecordingSchemaVersion {
+		return nil, fmt.Errorf("%s: unsupported recording version %d", path, rec.Version)
+	}
+	if err := migrateScene(&rec.Scene); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &rec, nil
+}
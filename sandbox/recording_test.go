@@ -0,0 +1,74 @@
+package sandbox
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/bondar-pavel/gravity/physics"
+)
+
+// record plays script on a world reset to sf, as the game does while
+// recording, and returns the finished recording.
+func record(t *testing.T, sf Scene, script []Action, endTick int) *Recording {
+	t.Helper()
+	w := physics.NewWorld(physics.DefaultConfig())
+	if err := ResetToScene(sf, w); err != nil {
+		t.Fatal(err)
+	}
+	rec := &Recording{Version: RecordingSchemaVersion, Scene: sf, Speed: 1}
+	for w.Tick() < endTick {
+		for _, a := range script {
+			if a.Tick == w.Tick() {
+				a.Apply(w)
+				rec.Actions = append(rec.Actions, a)
+			}
+		}
+		w.StepPhysics()
+	}
+	rec.EndTick = w.Tick()
+	rec.Checksum = StateChecksum(w)
+	return rec
+}
+
+func TestReplayReproducesRecording(t *testing.T) {
+	sf := EncodeScene(testWorld(), View{Zoom: 1})
+	// The scene's objects get IDs 1-7 on reset: the rocket is 4, the first
+	// launch is 8
+	rec := record(t, sf, []Action{
+		{Tick: 5, Kind: ActionLaunch, X: 700, Y: 400, VX: 0.5, VY: 0.2, Radius: 4, Material: MaterialName(physics.MaterialIce)},
+		{Tick: 20, Kind: ActionEngine, ID: 4, Value: 1, Turn: 0.5},
+		{Tick: 40, Kind: ActionToggleMerge},
+		{Tick: 60, Kind: ActionPin, ID: 8},
+		{Tick: 80, Kind: ActionPlace, Fixture: physics.KindDragZone.String(), X: 500, Y: 500, Radius: 50, Value: 0.1},
+		{Tick: 100, Kind: ActionConnect, ID: 8, Other: 2, Constraint: physics.ConstraintSpring.String()},
+		{Tick: 120, Kind: ActionEngine, ID: 4},
+		{Tick: 150, Kind: ActionDelete, ID: 3},
+	}, 300)
+	if rec.Checksum == record(t, sf, nil, 300).Checksum {
+		t.Fatal("the recorded actions did not change the outcome")
+	}
+
+	path := filepath.Join(t.TempDir(), "session.replay.json")
+	if err := SaveRecording(path, rec); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadRecording(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := physics.NewWorld(physics.DefaultConfig())
+	if err := ResetToScene(loaded.Scene, w); err != nil {
+		t.Fatal(err)
+	}
+	p := &Playback{Rec: loaded}
+	for w.Tick() < loaded.EndTick {
+		for _, a := range p.Due(w.Tick()) {
+			a.Apply(w)
+		}
+		w.StepPhysics()
+	}
+	if got := StateChecksum(w); got != loaded.Checksum {
+		t.Errorf("replay ended with checksum %s, recording %s", got, loaded.Checksum)
+	}
+}
@@ -0,0 +1,477 @@
+package sandbox
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/bondar-pavel/gravity/physics"
+)
+
+// SceneSchemaVersion is written to every saved scene. Bump it when the format
+// changes and teach migrateScene to upgrade the previous version.
+const SceneSchemaVersion = 3
+
+// Scene is the on-disk JSON representation of a sandbox.
+type Scene struct {
+	Version     int               `json:"version"`
+	World       sceneWorld        `json:"world"`
+	Camera      View              `json:"camera"`
+	Objects     []sceneObject     `json:"objects"`
+	Constraints []sceneConstraint `json:"constraints,omitempty"`
+	Swarm       *sceneSwarm       `json:"swarm,omitempty"`
+}
+
+type sceneWorld struct {
+	MergeOnCollision          bool    `json:"merge_on_collision"`
+	FragmentOnCollision       bool    `json:"fragment_on_collision"`
+	FragmentThreshold         float64 `json:"fragment_threshold"`
+	PhysicalEjecta            bool    `json:"physical_ejecta,omitempty"`
+	TidalDisruption           bool    `json:"tidal_disruption,omitempty"`
+	FrictionEnabled           bool    `json:"friction_enabled"`
+	FrictionCoeff             float64 `json:"friction_coeff"`
+	Restitution               float64 `json:"restitution"`
+	BounceOnScreenCollision   bool    `json:"bounce_on_screen_collision"`
+	BounceOnParticleCollision bool    `json:"bounce_on_particle_collision"`
+	GravityMode               string  `json:"gravity_mode"`
+	Integrator                string  `json:"integrator"`
+	Solver                    string  `json:"solver"`
+	BarnesHutTheta            float64 `json:"barnes_hut_theta"`
+	AdaptiveTimestep          bool    `json:"adaptive_timestep"`
+}
+
+// View is the camera a scene is shown with: the world point at the centre of
+// the screen and the zoom. A zero Zoom leaves the camera as it is.
+type View struct {
+	X    float64 `json:"x"`
+	Y    float64 `json:"y"`
+	Zoom float64 `json:"zoom"`
+}
+
+type sceneObject struct {
+	Kind            string  `json:"kind,omitempty"` // empty is a body
+	X               float64 `json:"x"`
+	Y               float64 `json:"y"`
+	VelocityX       float64 `json:"vx"`
+	VelocityY       float64 `json:"vy"`
+	Mass            float64 `json:"mass"`
+	Radius          float64 `json:"radius"`
+	Material        string  `json:"material,omitempty"` // empty is rock
+	Pinned          bool    `json:"pinned,omitempty"`
+	Color           [3]byte `json:"color"`
+	Angle           float64 `json:"angle,omitempty"`
+	AngularVelocity float64 `json:"angular_velocity,omitempty"`
+
+	// Fixture parameters
+	FieldX float64 `json:"field_x,omitempty"`
+	FieldY float64 `json:"field_y,omitempty"`
+	Drag   float64 `json:"drag,omitempty"`
+	Link   *int    `json:"link,omitempty"` // wormhole: index of the other mouth in objects
+
+	Engine *sceneEngine `json:"engine,omitempty"` // rockets only
+}
+
+// sceneEngine is a rocket's engine, see physics.Engine.
+type sceneEngine struct {
+	Heading  float64 `json:"heading"`
+	Throttle float64 `json:"throttle,omitempty"`
+	Turn     float64 `json:"turn,omitempty"`
+	Fuel     float64 `json:"fuel"`
+	BurnRate float64 `json:"burn_rate"`
+	Exhaust  float64 `json:"exhaust"`
+	Used     float64 `json:"used,omitempty"`
+}
+
+// validate rejects an engine that could not have come from FitEngine and
+// play: one whose propellant is not part of a body of the given mass, or
+// whose controls are out of range.
+func (e *sceneEngine) validate(mass float64) error {
+	switch {
+	case e.Fuel < 0 || e.Fuel >= mass:
+		return fmt.Errorf("fuel %v is not in [0, mass %v)", e.Fuel, mass)
+	case e.BurnRate <= 0:
+		return fmt.Errorf("burn_rate %v is not positive", e.BurnRate)
+	case e.Exhaust <= 0:
+		return fmt.Errorf("exhaust %v is not positive", e.Exhaust)
+	case e.Throttle < 0 || e.Throttle > 1:
+		return fmt.Errorf("throttle %v is not in [0, 1]", e.Throttle)
+	case e.Turn < -1 || e.Turn > 1:
+		return fmt.Errorf("turn %v is not in [-1, 1]", e.Turn)
+	}
+	return nil
+}
+
+// sceneConstraint links two objects, named by their index in objects.
+type sceneConstraint struct {
+	Kind      string  `json:"kind"`
+	A         int     `json:"a"`
+	B         int     `json:"b"`
+	Length    float64 `json:"length"`
+	Stiffness float64 `json:"stiffness,omitempty"`
+	Damping   float64 `json:"damping,omitempty"`
+}
+
+// sceneSwarm holds test particles as parallel arrays, like physics.Swarm.
+type sceneSwarm struct {
+	X  []float64 `json:"x"`
+	Y  []float64 `json:"y"`
+	VX []float64 `json:"vx"`
+	VY []float64 `json:"vy"`
+}
+
+// EncodeScene captures the world and the view it is shown with.
+func EncodeScene(world *physics.World, view View) Scene {
+	sf := Scene{
+		Version: SceneSchemaVersion,
+		World: sceneWorld{
+			MergeOnCollision:          world.MergeOnCollision,
+			FragmentOnCollision:       world.FragmentOnCollision,
+			FragmentThreshold:         world.FragmentThreshold,
+			PhysicalEjecta:            world.PhysicalEjecta,
+			TidalDisruption:           world.TidalDisruption,
+			FrictionEnabled:           world.FrictionEnabled,
+			FrictionCoeff:             world.FrictionCoeff,
+			Restitution:               world.Restitution,
+			BounceOnScreenCollision:   world.BounceOnScreenCollision,
+			BounceOnParticleCollision: world.BounceOnParticleCollision,
+			GravityMode:               world.GravityMode.String(),
+			Integrator:                world.IntegratorKind().String(),
+			Solver:                    world.Solver.String(),
+			BarnesHutTheta:            world.BarnesHutTheta,
+			AdaptiveTimestep:          world.AdaptiveTimestep,
+		},
+		Camera:  view,
+		Objects: make([]sceneObject, 0, len(world.Objects())),
+	}
+	index := make(map[physics.ObjectID]int, len(world.Objects()))
+	for i, o := range world.Objects() {
+		index[o.ID] = i
+	}
+	for _, o := range world.Objects() {
+		var link *int
+		if i, ok := index[o.Link]; ok {
+			link = &i
+		}
+		var engine *sceneEngine
+		if o.Rocket() {
+			e := o.Engine
+			engine = &sceneEngine{
+				Heading:  e.Heading,
+				Throttle: e.Throttle,
+				Turn:     e.Turn,
+				Fuel:     e.Fuel,
+				BurnRate: e.BurnRate,
+				Exhaust:  e.Exhaust,
+				Used:     e.Used,
+			}
+		}
+		sf.Objects = append(sf.Objects, sceneObject{
+			Kind:            KindName(o.Kind),
+			X:               o.X,
+			Y:               o.Y,
+			VelocityX:       o.VelocityX,
+			VelocityY:       o.VelocityY,
+			Mass:            o.Mass,
+			Radius:          o.Radius,
+			Material:        MaterialName(o.Material),
+			Pinned:          o.Pinned,
+			Color:           o.Color,
+			Angle:           o.Angle,
+			AngularVelocity: o.AngularVelocity,
+			FieldX:          o.FieldX,
+			FieldY:          o.FieldY,
+			Drag:            o.Drag,
+			Link:            link,
+			Engine:          engine,
+		})
+	}
+	for _, c := range world.Constraints() {
+		a, okA := index[c.A]
+		b, okB := index[c.B]
+		if !okA || !okB {
+			continue
+		}
+		sf.Constraints = append(sf.Constraints, sceneConstraint{
+			Kind:      c.Kind.String(),
+			A:         a,
+			B:         b,
+			Length:    c.Length,
+			Stiffness: c.Stiffness,
+			Damping:   c.Damping,
+		})
+	}
+	if s := world.Swarm(); s.Len() > 0 {
+		sf.Swarm = &sceneSwarm{
+			X:  append([]float64(nil), s.X...),
+			Y:  append([]float64(nil), s.Y...),
+			VX: append([]float64(nil), s.VX...),
+			VY: append([]float64(nil), s.VY...),
+		}
+	}
+	return sf
+}
+
+// ApplyScene replaces the world's contents and settings with the scene; the
+// caller moves its camera to sf.Camera. The scene is validated before
+// anything is modified.
+func ApplyScene(sf Scene, world *physics.World) error {
+	mode, err := ParseEnum(sf.World.GravityMode, physics.GravityLegacy, physics.GravityNewtonian)
+	if err != nil {
+		return fmt.Errorf("gravity_mode: %w", err)
+	}
+	solver, err := ParseEnum(sf.World.Solver, physics.SolverDirect, physics.SolverBarnesHut)
+	if err != nil {
+		return fmt.Errorf("solver: %w", err)
+	}
+	integrators := make([]physics.IntegratorKind, physics.NumIntegrators)
+	for k := range integrators {
+		integrators[k] = physics.IntegratorKind(k)
+	}
+	integrator, err := ParseEnum(sf.World.Integrator, integrators...)
+	if err != nil {
+		return fmt.Errorf("integrator: %w", err)
+	}
+	objMaterials := make([]physics.Material, len(sf.Objects))
+	objKinds := make([]physics.ObjectKind, len(sf.Objects))
+	for i, so := range sf.Objects {
+		if objMaterials[i], err = ParseMaterial(so.Material); err != nil {
+			return fmt.Errorf("object %d material: %w", i, err)
+		}
+		if objKinds[i], err = ParseKind(so.Kind); err != nil {
+			return fmt.Errorf("object %d kind: %w", i, err)
+		}
+		if so.Radius <= 0 {
+			return fmt.Errorf("object %d radius: %v is not positive", i, so.Radius)
+		}
+		// Gravity divides by a body's mass; fixtures take theirs from their kind
+		if objKinds[i] == physics.KindBody && so.Mass <= 0 {
+			return fmt.Errorf("object %d mass: %v is not positive", i, so.Mass)
+		}
+		if so.Link != nil && (*so.Link < 0 || *so.Link >= len(sf.Objects)) {
+			return fmt.Errorf("object %d link: no object %d", i, *so.Link)
+		}
+		if so.Engine != nil {
+			if err := so.Engine.validate(so.Mass); err != nil {
+				return fmt.Errorf("object %d engine: %w", i, err)
+			}
+		}
+	}
+	constraintKinds := make([]physics.ConstraintKind, len(sf.Constraints))
+	for i, sc := range sf.Constraints {
+		if constraintKinds[i], err = ParseConstraint(sc.Kind); err != nil {
+			return fmt.Errorf("constraint %d kind: %w", i, err)
+		}
+		n := len(sf.Objects)
+		if sc.A < 0 || sc.A >= n || sc.B < 0 || sc.B >= n || sc.A == sc.B {
+			return fmt.Errorf("constraint %d: objects %d and %d out of range", i, sc.A, sc.B)
+		}
+	}
+	if sf.World.FragmentThreshold <= 0 {
+		return fmt.Errorf("fragment_threshold: %v is not positive", sf.World.FragmentThreshold)
+	}
+	if sw := sf.Swarm; sw != nil {
+		if n := len(sw.X); len(sw.Y) != n || len(sw.VX) != n || len(sw.VY) != n {
+			return fmt.Errorf("swarm: x, y, vx and vy lengths differ")
+		}
+	}
+
+	world.MergeOnCollision = sf.World.MergeOnCollision
+	world.FragmentOnCollision = sf.World.FragmentOnCollision
+	world.FragmentThreshold = sf.World.FragmentThreshold
+	world.PhysicalEjecta = sf.World.PhysicalEjecta
+	world.TidalDisruption = sf.World.TidalDisruption
+	world.FrictionEnabled = sf.World.FrictionEnabled
+	world.FrictionCoeff = sf.World.FrictionCoeff
+	world.Restitution = sf.World.Restitution
+	world.BounceOnScreenCollision = sf.World.BounceOnScreenCollision
+	world.BounceOnParticleCollision = sf.World.BounceOnParticleCollision
+	world.GravityMode = mode
+	world.Solver = solver
+	world.BarnesHutTheta = sf.World.BarnesHutTheta
+	world.AdaptiveTimestep = sf.World.AdaptiveTimestep
+
+	world.Clear()
+	objs := make([]*physics.Object, len(sf.Objects))
+	for i, so := range sf.Objects {
+		var obj *physics.Object
+		if objKinds[i] == physics.KindBody {
+			obj = world.AddObject(so.X, so.Y, so.Radius)
+			obj.VelocityX = so.VelocityX
+			obj.VelocityY = so.VelocityY
+			obj.Mass = so.Mass
+			obj.Pinned = so.Pinned
+		} else {
+			// Pinned, with the mass of its kind, whatever the file says
+			obj = world.AddFixture(objKinds[i], so.X, so.Y, so.Radius)
+		}
+		obj.Material = objMaterials[i]
+		obj.Color = so.Color
+		obj.Angle = so.Angle
+		obj.AngularVelocity = so.AngularVelocity
+		obj.FieldX = so.FieldX
+		obj.FieldY = so.FieldY
+		obj.Drag = so.Drag
+		if e := so.Engine; e != nil {
+			obj.Engine = physics.Engine{
+				Heading:  e.Heading,
+				Throttle: e.Throttle,
+				Turn:     e.Turn,
+				Fuel:     e.Fuel,
+				BurnRate: e.BurnRate,
+				Exhaust:  e.Exhaust,
+				Used:     e.Used,
+			}
+		}
+		objs[i] = obj
+	}
+	for i, so := range sf.Objects {
+		if so.Link != nil {
+			objs[i].Link = objs[*so.Link].ID
+		}
+	}
+	for i, sc := range sf.Constraints {
+		world.AddConstraint(physics.Constraint{
+			Kind:      constraintKinds[i],
+			A:         objs[sc.A].ID,
+			B:         objs[sc.B].ID,
+			Length:    sc.Length,
+			Stiffness: sc.Stiffness,
+			Damping:   sc.Damping,
+		})
+	}
+	// Also seeds the stored accelerations for the new objects
+	world.SetIntegrator(integrator)
+	if sw := sf.Swarm; sw != nil {
+		for i := range sw.X {
+			world.AddTestParticle(sw.X[i], sw.Y[i], sw.VX[i], sw.VY[i])
+		}
+	}
+
+	if world.TrackDiagnostics {
+		world.ResetDiagnostics()
+	}
+	return nil
+}
+
+// migrateScene upgrades an older scene to the current schema in place.
+func migrateScene(sf *Scene) error {
+	if sf.Version > SceneSchemaVersion {
+		return fmt.Errorf("scene version %d is newer than supported version %d", sf.Version, SceneSchemaVersion)
+	}
+	for sf.Version < SceneSchemaVersion {
+		switch sf.Version {
+		case 1:
+			// Version 2 added fragmentation, off by default
+			sf.World.FragmentThreshold = physics.DefaultFragmentThreshold
+			sf.Version = 2
+		case 2:
+			// Version 3 added materials, fixtures, rockets, constraints, test
+			// particles, physical ejecta and tidal disruption. Older files
+			// have none of them, which is what their absence means, and their
+			// explicit masses are rock masses, so nothing needs changing;
+			// the new version keeps older builds from dropping the new parts
+			sf.Version = 3
+		default:
+			return fmt.Errorf("unknown scene version %d", sf.Version)
+		}
+	}
+	return nil
+}
+
+// DecodeScene parses and migrates scene JSON.
+func DecodeScene(data []byte) (Scene, error) {
+	var sf Scene
+	if err := json.Unmarshal(data, &sf); err != nil {
+		return sf, err
+	}
+	if err := migrateScene(&sf); err != nil {
+		return sf, err
+	}
+	return sf, nil
+}
+
+// SaveScene writes sf to path, creating its directory.
+func SaveScene(path string, sf Scene) error {
+	data, err := json.MarshalIndent(sf, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// LoadScene reads and migrates the scene stored at path.
+func LoadScene(path string) (Scene, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Scene{}, err
+	}
+	sf, err := DecodeScene(data)
+	if err != nil {
+		return Scene{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return sf, nil
+}
+
+// ParseMaterial parses a material name; the empty name is rock.
+func ParseMaterial(name string) (physics.Material, error) {
+	if name == "" {
+		return physics.MaterialRock, nil
+	}
+	materials := make([]physics.Material, physics.NumMaterials)
+	for m := range materials {
+		materials[m] = physics.Material(m)
+	}
+	return ParseEnum(name, materials...)
+}
+
+// MaterialName is the inverse of ParseMaterial, leaving rock out of files.
+func MaterialName(m physics.Material) string {
+	if m == physics.MaterialRock {
+		return ""
+	}
+	return m.String()
+}
+
+// ParseKind parses an object kind name; the empty name is a body.
+func ParseKind(name string) (physics.ObjectKind, error) {
+	if name == "" {
+		return physics.KindBody, nil
+	}
+	kinds := make([]physics.ObjectKind, physics.NumKinds)
+	for k := range kinds {
+		kinds[k] = physics.ObjectKind(k)
+	}
+	return ParseEnum(name, kinds...)
+}
+
+// KindName is the inverse of ParseKind, leaving bodies out of files.
+func KindName(k physics.ObjectKind) string {
+	if k == physics.KindBody {
+		return ""
+	}
+	return k.String()
+}
+
+// ParseConstraint parses a constraint kind name.
+func ParseConstraint(name string) (physics.ConstraintKind, error) {
+	kinds := make([]physics.ConstraintKind, physics.NumConstraintKinds)
+	for k := range kinds {
+		kinds[k] = physics.ConstraintKind(k)
+	}
+	return ParseEnum(name, kinds...)
+}
+
+// ParseEnum returns the value whose String() matches name.
+func ParseEnum[T fmt.Stringer](name string, values ...T) (T, error) {
+	for _, v := range values {
+		if v.String() == name {
+			return v, nil
+		}
+	}
+	var zero T
+	return zero, fmt.Errorf("unknown value %q", name)
+}
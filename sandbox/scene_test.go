@@ -0,0 +1,131 @@
+package sandbox
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bondar-pavel/gravity/physics"
+)
+
+// testWorld returns a world with one of everything a scene stores: bodies of
+// two materials, a rocket, linked wormholes, a gravity zone, a constraint and
+// test particles.
+func testWorld() *physics.World {
+	w := physics.NewWorld(physics.DefaultConfig())
+	w.GravityMode = physics.GravityNewtonian
+	star := w.AddObject(800, 600, 20)
+	star.Pinned = true
+	planet := w.AddObject(1000, 600, 6)
+	planet.SetMaterial(physics.MaterialIce)
+	planet.VelocityY = 0.4
+	moon := w.AddObject(1020, 600, 2)
+	w.Connect(physics.ConstraintRod, planet, moon)
+	rocket := w.AddObject(600, 600, 4)
+	rocket.FitEngine(physics.DefaultFuelFraction)
+	physics.LinkWormholes(w.AddFixture(physics.KindWormhole, 300, 300, 15), w.AddFixture(physics.KindWormhole, 1300, 900, 15))
+	zone := w.AddFixture(physics.KindGravityZone, 800, 1000, 100)
+	zone.FieldY = 0.02
+	ScatterSwarm(w, star, 60, 120, 50, 1)
+	return w
+}
+
+func TestSceneRoundTrip(t *testing.T) {
+	want := EncodeScene(testWorld(), View{X: 800, Y: 600, Zoom: 1.5})
+	data, err := json.Marshal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := DecodeScene(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := physics.NewWorld(physics.DefaultConfig())
+	if err := ApplyScene(decoded, w); err != nil {
+		t.Fatal(err)
+	}
+	if got := EncodeScene(w, decoded.Camera); !reflect.DeepEqual(got, want) {
+		t.Errorf("scene changed on a round trip:\ngot  %+v\nwant %+v", got, want)
+	}
+}
+
+func TestApplySceneRejectsBadScenes(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		edit  func(sf *Scene)
+		error string
+	}{
+		{"unknown gravity", func(sf *Scene) { sf.World.GravityMode = "MOND" }, "gravity_mode"},
+		{"unknown material", func(sf *Scene) { sf.Objects[1].Material = "cheese" }, "object 1 material"},
+		{"zero radius", func(sf *Scene) { sf.Objects[1].Radius = 0 }, "object 1 radius"},
+		{"missing mass", func(sf *Scene) { sf.Objects[1].Mass = 0 }, "object 1 mass"},
+		{"negative mass", func(sf *Scene) { sf.Objects[2].Mass = -5 }, "object 2 mass"},
+		{"dangling link", func(sf *Scene) { l := 99; sf.Objects[4].Link = &l }, "object 4 link"},
+		{"fuel outweighs rocket", func(sf *Scene) { sf.Objects[3].Engine.Fuel = sf.Objects[3].Mass }, "object 3 engine"},
+		{"constraint to itself", func(sf *Scene) { sf.Constraints[0].B = sf.Constraints[0].A }, "constraint 0"},
+		{"no fragment threshold", func(sf *Scene) { sf.World.FragmentThreshold = 0 }, "fragment_threshold"},
+		{"ragged swarm", func(sf *Scene) { sf.Swarm.VY = sf.Swarm.VY[1:] }, "swarm"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			w := testWorld()
+			before := EncodeScene(w, View{})
+			sf := EncodeScene(w, View{})
+			tc.edit(&sf)
+			err := ApplyScene(sf, w)
+			if err == nil || !strings.Contains(err.Error(), tc.error) {
+				t.Fatalf("got error %v, want one about %s", err, tc.error)
+			}
+			if !reflect.DeepEqual(EncodeScene(w, View{}), before) {
+				t.Errorf("world was modified by a rejected scene")
+			}
+		})
+	}
+}
+
+func TestDecodeSceneMigratesOldVersions(t *testing.T) {
+	for _, data := range []string{
+		`{"version": 1, "world": {"gravity_mode": "Newtonian", "integrator": "Verlet", "solver": "Direct"},
+		  "objects": [{"x": 100, "y": 100, "vx": 0, "vy": 1, "mass": 100, "radius": 10, "color": [1, 2, 3]}]}`,
+		`{"version": 2, "world": {"fragment_threshold": 2, "gravity_mode": "Legacy", "integrator": "Verlet", "solver": "Direct"},
+		  "objects": [{"x": 100, "y": 100, "vx": 0, "vy": 1, "mass": 100, "radius": 10, "color": [1, 2, 3]}]}`,
+	} {
+		sf, err := DecodeScene([]byte(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sf.Version != SceneSchemaVersion {
+			t.Errorf("migrated to version %d, want %d", sf.Version, SceneSchemaVersion)
+		}
+		if err := ApplyScene(sf, physics.NewWorld(physics.DefaultConfig())); err != nil {
+			t.Errorf("migrated scene does not load: %v", err)
+		}
+	}
+	if _, err := DecodeScene([]byte(`{"version": 99}`)); err == nil {
+		t.Error("a scene from a newer version was accepted")
+	}
+}
+
+// A fixture is pinned however it was saved: massless zones would otherwise be
+// flung away by legacy gravity, which divides by the mass.
+func TestApplyScenePinsFixtures(t *testing.T) {
+	w := testWorld()
+	w.GravityMode = physics.GravityLegacy
+	sf := EncodeScene(w, View{})
+	for i := range sf.Objects {
+		sf.Objects[i].Pinned = false
+	}
+	if err := ApplyScene(sf, w); err != nil {
+		t.Fatal(err)
+	}
+	w.StepPhysics()
+	for _, o := range w.Objects() {
+		if o.Kind == physics.KindBody {
+			continue
+		}
+		if !o.Pinned || math.IsNaN(o.X) || math.IsInf(o.X, 0) {
+			t.Errorf("%v at (%v, %v), pinned %v", o.Kind, o.X, o.Y, o.Pinned)
+		}
+	}
+}
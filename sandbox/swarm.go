@@ -0,0 +1,31 @@
+package sandbox
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/bondar-pavel/gravity/physics"
+)
+
+// Swarm scattering by ActionScatterSwarm, around the selected object.
+const (
+	swarmBatch = 2000 // test particles per action
+	swarmInner = 3    // disc inner edge, in radii of the host
+	swarmWidth = 200  // disc width in world pixels
+)
+
+// ScatterSwarm adds count test particles on circular orbits around host, at
+// distances spread evenly between inner and outer. Placement is
+// pseudo-random but reproducible from seed.
+func ScatterSwarm(world *physics.World, host *physics.Object, inner, outer float64, count int, seed int64) {
+	rng := rand.New(rand.NewSource(seed))
+	for i := 0; i < count; i++ {
+		r := inner + (outer-inner)*rng.Float64()
+		angle := 2 * math.Pi * rng.Float64()
+		v := world.CircularSpeed(r, host.Mass, physics.TracerMass)
+		// Counterclockwise on screen (Y down), as for preset orbits
+		world.AddTestParticle(
+			host.X+r*math.Cos(angle), host.Y-r*math.Sin(angle),
+			host.VelocityX-v*math.Sin(angle), host.VelocityY-v*math.Cos(angle))
+	}
+}
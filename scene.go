@@ -0,0 +1,49 @@
+package main
+
+import (
+	"github.com/bondar-pavel/gravity/physics"
+	"github.com/bondar-pavel/gravity/sandbox"
+)
+
+const scenesDir = "scenes"
+const quickSavePath = scenesDir + "/quicksave.json"
+
+// view returns the camera as scenes store it.
+func (c *Camera) view() sandbox.View {
+	return sandbox.View{X: c.x, Y: c.y, Zoom: c.zoom}
+}
+
+// setView moves the camera to a view read from a scene; a zero zoom leaves
+// it where it is.
+func (c *Camera) setView(v sandbox.View) {
+	if v.Zoom > 0 {
+		c.x, c.y, c.zoom = v.X, v.Y, v.Zoom
+	}
+}
+
+// SaveScene writes the world and camera to path, creating its directory.
+func SaveScene(path string, world *physics.World, cam *Camera) error {
+	return sandbox.SaveScene(path, sandbox.EncodeScene(world, cam.view()))
+}
+
+// LoadScene replaces the world and camera with the scene stored at path.
+func LoadScene(path string, world *physics.World, cam *Camera) error {
+	sf, err := sandbox.LoadScene(path)
+	if err != nil {
+		return err
+	}
+	if err := sandbox.ApplyScene(sf, world); err != nil {
+		return err
+	}
+	cam.setView(sf.Camera)
+	return nil
+}
+
+// applyPreset loads a preset and resets the camera to its zoom.
+func applyPreset(p sandbox.Preset, world *physics.World, cam *Camera) {
+	p.Apply(world)
+	cam.Reset()
+	if p.Zoom > 0 {
+		cam.zoom = p.Zoom
+	}
+}
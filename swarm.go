@@ -0,0 +1,23 @@
+package main
+
+import "github.com/bondar-pavel/gravity/physics"
+
+// swarmColor is added to a pixel per test particle, so dense regions glow.
+var swarmColor = [3]byte{40, 55, 80}
+
+// drawSwarm plots each test particle as one additive pixel.
+func (r *Renderer) drawSwarm(world *physics.World, cam *Camera) {
+	s := world.Swarm()
+	for i := 0; i < s.Len(); i++ {
+		sx, sy := cam.WorldToScreen(s.X[i], s.Y[i])
+		ix, iy := int(sx), int(sy)
+		if ix < 0 || ix >= screenWidth || iy < 0 || iy >= screenHeight {
+			continue
+		}
+		idx := (iy*screenWidth + ix) * 4
+		for c := 0; c < 3; c++ {
+			r.pixels[idx+c] = byte(min(255, int(r.pixels[idx+c])+int(swarmColor[c])))
+		}
+		r.pixels[idx+3] = 0xFF
+	}
+}
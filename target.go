@@ -1,6 +1,10 @@
 package main
 
-import "math"
+import (
+	"math"
+
+	"github.com/bondar-pavel/gravity/physics"
+)
 
 type TargetState int
 
@@ -17,10 +21,11 @@ type TargetZone struct {
 }
 
 type TargetLevel struct {
-	Name    string
-	Objects []LevelObject
-	Targets []TargetZone
-	Par     int
+	Name     string
+	Objects  []LevelObject
+	Fixtures []LevelFixture
+	Targets  []TargetZone
+	Par      int
 }
 
 type TargetPractice struct {
@@ -30,17 +35,22 @@ type TargetPractice struct {
 	levels       []TargetLevel
 
 	// Current attempt
-	projectile *Object
-	targets    []TargetZone // mutable copy for current attempt
-	launches   int
-	bestStars  []int // best star rating per level
+	projectileID physics.ObjectID
+	targets      []TargetZone // mutable copy for current attempt
+	launches     int
+	bestStars    []int // best star rating per level
 
 	// Result display
 	resultTimer int
 
 	// Saved sandbox state
-	savedObjects []*Object
-	savedMerge   bool
+	savedObjects     []*physics.Object
+	savedConstraints []physics.Constraint
+	savedMerge       bool
+	savedCollide     bool
+	savedGravity     physics.GravityMode
+
+	unsubscribe func() // detaches handleEvent from the world
 }
 
 func newTargetPractice() *TargetPractice {
@@ -92,6 +102,35 @@ func newTargetPractice() *TargetPractice {
 			},
 			Par: 2,
 		},
+		{
+			Name: "Wormhole Express",
+			Objects: []LevelObject{
+				{800, 600, 30, true},
+			},
+			Fixtures: []LevelFixture{
+				{Kind: physics.KindWormhole, X: 450, Y: 600, Radius: 25, Link: 1},
+				{Kind: physics.KindWormhole, X: 1150, Y: 600, Radius: 25, Link: 0},
+			},
+			Targets: []TargetZone{
+				{1400, 600, 35, false},
+				{800, 250, 35, false},
+			},
+			Par: 2,
+		},
+		{
+			Name: "Crosswind",
+			Objects: []LevelObject{
+				{800, 750, 30, true},
+			},
+			Fixtures: []LevelFixture{
+				{Kind: physics.KindGravityZone, X: 800, Y: 400, Radius: 200, FieldX: 0.03},
+			},
+			Targets: []TargetZone{
+				{550, 300, 35, false},
+				{1050, 300, 35, false},
+			},
+			Par: 2,
+		},
 	}
 
 	return &TargetPractice{
@@ -100,52 +139,61 @@ func newTargetPractice() *TargetPractice {
 	}
 }
 
-func (tp *TargetPractice) Enter(world *World) {
-	tp.savedObjects = make([]*Object, len(world.objects))
-	copy(tp.savedObjects, world.objects)
-	tp.savedMerge = world.mergeOnCollision
+func (tp *TargetPractice) Enter(world *physics.World) {
+	tp.savedObjects = make([]*physics.Object, len(world.Objects()))
+	copy(tp.savedObjects, world.Objects())
+	tp.savedConstraints = append([]physics.Constraint(nil), world.Constraints()...)
+	tp.savedMerge = world.MergeOnCollision
+	tp.savedCollide = world.BounceOnParticleCollision
+	tp.savedGravity = world.GravityMode
 
 	tp.active = true
-	tp.projectile = nil
-	world.mergeOnCollision = false
+	tp.projectileID = physics.NoObject
+	world.MergeOnCollision = false
+	world.BounceOnParticleCollision = true    // crashes are detected by collision events
+	world.GravityMode = physics.GravityLegacy // levels are tuned for arcade gravity
+	tp.unsubscribe = world.Subscribe(func(e physics.Event) { tp.handleEvent(world, e) })
 	tp.loadLevel(world)
 }
 
-func (tp *TargetPractice) Exit(world *World) {
+func (tp *TargetPractice) Exit(world *physics.World) {
 	tp.active = false
-	if tp.projectile != nil {
-		world.RemoveObject(tp.projectile)
-		tp.projectile = nil
-	}
-
-	world.objects = tp.savedObjects
-	world.mergeOnCollision = tp.savedMerge
+	world.RemoveID(tp.projectileID)
+	tp.projectileID = physics.NoObject
+	tp.unsubscribe()
+	tp.unsubscribe = nil
+
+	world.ReplaceObjects(tp.savedObjects)
+	world.SetConstraints(tp.savedConstraints)
+	world.MergeOnCollision = tp.savedMerge
+	world.BounceOnParticleCollision = tp.savedCollide
+	world.GravityMode = tp.savedGravity
 	tp.savedObjects = nil
+	tp.savedConstraints = nil
 }
 
-func (tp *TargetPractice) loadLevel(world *World) {
+func (tp *TargetPractice) loadLevel(world *physics.World) {
 	level := tp.levels[tp.currentLevel]
-	world.objects = world.objects[:0]
+	world.Clear()
 
 	for _, lo := range level.Objects {
 		obj := world.AddObject(lo.X, lo.Y, lo.Radius)
-		obj.pinned = lo.Pinned
+		obj.Pinned = lo.Pinned
 	}
+	addLevelFixtures(world, level.Fixtures)
 
 	// Copy targets fresh
 	tp.targets = make([]TargetZone, len(level.Targets))
 	copy(tp.targets, level.Targets)
 
-	if tp.projectile != nil {
-		world.RemoveObject(tp.projectile)
-	}
-	tp.projectile = nil
+	world.RemoveID(tp.projectileID)
+	tp.projectileID = physics.NoObject
 	tp.state = TargetAiming
 	tp.launches = 0
 	tp.resultTimer = 0
 }
 
-func (tp *TargetPractice) ChangeLevel(delta int, world *World) {
+func (tp *TargetPractice) ChangeLevel(delta int, world *physics.World) {
 	if tp.state == TargetFlying {
 		return
 	}
@@ -159,27 +207,25 @@ func (tp *TargetPractice) ChangeLevel(delta int, world *World) {
 	tp.loadLevel(world)
 }
 
-func (tp *TargetPractice) LaunchProjectile(world *World, x, y, vx, vy float64) {
+func (tp *TargetPractice) LaunchProjectile(world *physics.World, x, y, vx, vy float64) {
 	if tp.state != TargetAiming {
 		return
 	}
 
 	// Remove previous projectile if any
-	if tp.projectile != nil {
-		world.RemoveObject(tp.projectile)
-	}
+	world.RemoveID(tp.projectileID)
 
 	obj := world.AddObject(x, y, 5)
-	obj.velocityX = vx
-	obj.velocityY = vy
-	obj.color = [3]byte{100, 255, 200} // bright cyan-green
+	obj.VelocityX = vx
+	obj.VelocityY = vy
+	obj.Color = [3]byte{100, 255, 200} // bright cyan-green
 
-	tp.projectile = obj
+	tp.projectileID = obj.ID
 	tp.state = TargetFlying
 	tp.launches++
 }
 
-func (tp *TargetPractice) Update(world *World) {
+func (tp *TargetPractice) Update(world *physics.World) {
 	if !tp.active {
 		return
 	}
@@ -192,8 +238,9 @@ func (tp *TargetPractice) Update(world *World) {
 	}
 }
 
-func (tp *TargetPractice) trackProjectile(world *World) {
-	if tp.projectile == nil {
+func (tp *TargetPractice) trackProjectile(world *physics.World) {
+	projectile := world.Object(tp.projectileID)
+	if projectile == nil {
 		tp.state = TargetAiming
 		return
 	}
@@ -205,8 +252,8 @@ func (tp *TargetPractice) trackProjectile(world *World) {
 		if t.Hit {
 			continue
 		}
-		dx := tp.projectile.x - t.X
-		dy := tp.projectile.y - t.Y
+		dx := projectile.X - t.X
+		dy := projectile.Y - t.Y
 		dist := math.Sqrt(dx*dx + dy*dy)
 		if dist < t.Radius {
 			t.Hit = true
@@ -218,42 +265,37 @@ func (tp *TargetPractice) trackProjectile(world *World) {
 
 	if allHit {
 		tp.completeLevel(world)
-		return
 	}
+}
 
-	// Check crash into planet
-	for _, o := range world.objects {
-		if o == tp.projectile || !o.pinned {
-			continue
-		}
-		dx := tp.projectile.x - o.x
-		dy := tp.projectile.y - o.y
-		dist := math.Sqrt(dx*dx + dy*dy)
-		if dist < float64(tp.projectile.radius+o.radius) {
+// handleEvent ends the shot when the projectile hits a planet or escapes far
+// enough to be culled.
+func (tp *TargetPractice) handleEvent(world *physics.World, e physics.Event) {
+	if tp.state != TargetFlying || tp.projectileID == physics.NoObject {
+		return
+	}
+	id := tp.projectileID
+	switch e.Kind {
+	case physics.EventCollision, physics.EventMerge, physics.EventFragment, physics.EventDisrupted,
+		physics.EventAbsorbed:
+		if e.A == id || e.B == id {
 			tp.removeProjectile(world)
-			return
 		}
-	}
-
-	// Check escape (far from screen center)
-	cx := float64(screenWidth) / 2
-	cy := float64(screenHeight) / 2
-	dx := tp.projectile.x - cx
-	dy := tp.projectile.y - cy
-	if dx*dx+dy*dy > cullDistance*cullDistance {
-		tp.removeProjectile(world)
+	case physics.EventRemoved:
+		if e.A == id {
+			tp.projectileID = physics.NoObject
+			tp.state = TargetAiming
+		}
 	}
 }
 
-func (tp *TargetPractice) removeProjectile(world *World) {
-	if tp.projectile != nil {
-		world.RemoveObject(tp.projectile)
-		tp.projectile = nil
-	}
+func (tp *TargetPractice) removeProjectile(world *physics.World) {
+	world.RemoveID(tp.projectileID)
+	tp.projectileID = physics.NoObject
 	tp.state = TargetAiming
 }
 
-func (tp *TargetPractice) completeLevel(world *World) {
+func (tp *TargetPractice) completeLevel(world *physics.World) {
 	tp.state = TargetComplete
 	tp.resultTimer = 0
 
@@ -262,10 +304,8 @@ func (tp *TargetPractice) completeLevel(world *World) {
 		tp.bestStars[tp.currentLevel] = stars
 	}
 
-	if tp.projectile != nil {
-		world.RemoveObject(tp.projectile)
-		tp.projectile = nil
-	}
+	world.RemoveID(tp.projectileID)
+	tp.projectileID = physics.NoObject
 }
 
 func (tp *TargetPractice) StarRating() int {
@@ -283,7 +323,7 @@ func (tp *TargetPractice) StarRating() int {
 	return 0
 }
 
-func (tp *TargetPractice) RetryLevel(world *World) {
+func (tp *TargetPractice) RetryLevel(world *physics.World) {
 	tp.loadLevel(world)
 }
 
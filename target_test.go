@@ -0,0 +1,31 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/bondar-pavel/gravity/physics"
+)
+
+// A projectile flown into a planet must crash even when the sandbox had
+// particle collisions off, as after loading the Trojan preset.
+func TestProjectileCrashesWithCollisionsOff(t *testing.T) {
+	world := physics.NewWorld(physics.DefaultConfig())
+	world.BounceOnParticleCollision = false
+	tp := newTargetPractice()
+	tp.Enter(world)
+
+	planet := world.Objects()[0]
+	tp.LaunchProjectile(world, planet.X, planet.Y+planet.Radius+20, 0, -2)
+	for i := 0; i < 100 && tp.state == TargetFlying; i++ {
+		world.StepPhysics()
+		tp.Update(world)
+	}
+	if tp.state != TargetAiming || tp.projectileID != physics.NoObject {
+		t.Fatalf("projectile did not crash: state %v, projectile %v", tp.state, tp.projectileID)
+	}
+
+	tp.Exit(world)
+	if world.BounceOnParticleCollision {
+		t.Error("Exit did not restore particle collisions to off")
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+
+	"github.com/bondar-pavel/gravity/physics"
+)
+
+const (
+	timelineInterval = 10  // ticks between snapshots
+	timelineCapacity = 300 // snapshots kept (3000 ticks, ~25 s at 1x)
+)
+
+// Timeline scrubber layout in screen pixels, above the status line.
+const (
+	timelineLeft   = 16
+	timelineRight  = screenWidth - 16
+	timelineTop    = screenHeight - 232
+	timelineHeight = 16
+)
+
+// handleTimeline steps through recorded snapshots with the arrow keys, unless
+// a rocket is selected, and scrubs with the mouse on the timeline bar.
+// Resuming while viewing the past branches: the snapshots after that point
+// are discarded and recording continues from there. It reports whether the
+// mouse was used by the scrubber, so the click does not also spawn a
+// particle.
+func (s *InputState) handleTimeline(world *physics.World) bool {
+	if s.recording != nil {
+		return false // rewinding would desynchronize the action log
+	}
+	tl := s.timeline
+	if !s.paused && s.timelinePos >= 0 {
+		tl.Branch(s.timelinePos)
+		s.timelinePos = -1
+	}
+
+	arrows := s.flying(world) == nil
+	if arrows && s.justPressed(ebiten.KeyArrowLeft) && tl.Len() > 0 {
+		if s.timelinePos < 0 {
+			// Keep the live state so stepping forward returns to it
+			tl.Capture(world)
+			s.timelinePos = tl.Len() - 1
+		}
+		s.seekTimeline(world, s.timelinePos-1)
+	}
+	if arrows && s.justPressed(ebiten.KeyArrowRight) && s.timelinePos >= 0 {
+		s.seekTimeline(world, s.timelinePos+1)
+	}
+
+	if !s.showTimeline() || s.aiming || s.dragging {
+		s.scrubbing = false
+		return false
+	}
+	cx, cy := ebiten.CursorPosition()
+	if !ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
+		s.scrubbing = false
+		return false
+	}
+	onBar := cx >= timelineLeft && cx <= timelineRight &&
+		cy >= timelineTop && cy <= timelineTop+timelineHeight
+	if !onBar && !s.scrubbing {
+		return false
+	}
+	if !s.scrubbing && s.timelinePos < 0 {
+		tl.Capture(world)
+	}
+	s.scrubbing = true
+	frac := float64(cx-timelineLeft) / float64(timelineRight-timelineLeft)
+	pos := int(frac*float64(tl.Len()-1) + 0.5)
+	if pos != s.timelinePos {
+		s.seekTimeline(world, pos)
+	}
+	return true
+}
+
+// seekTimeline pauses and restores the snapshot at pos, clamped to the
+// recorded range.
+func (s *InputState) seekTimeline(world *physics.World, pos int) {
+	pos = max(0, min(pos, s.timeline.Len()-1))
+	s.timelinePos = pos
+	world.Restore(s.timeline.At(pos))
+	s.paused = true
+	// IDs are handed out again after a rewind, so a reference to an object
+	// from the abandoned future could come to name a different one
+	if world.Object(s.selectedID) == nil {
+		s.selectedID = physics.NoObject
+	}
+	if world.Object(s.openMouth) == nil {
+		s.openMouth = physics.NoObject
+	}
+}
+
+// showTimeline reports whether the scrubber is visible: while paused with
+// at least two snapshots to choose between, and not recording a session.
+func (s *InputState) showTimeline() bool {
+	return s.paused && s.recording == nil && s.timeline.Len() > 1
+}
+
+// resetTimeline discards the history, e.g. after loading a different scene.
+func (s *InputState) resetTimeline() {
+	s.timeline.Clear()
+	s.timelinePos = -1
+	s.scrubbing = false
+}
+
+// drawTimeline draws the scrubber bar with a tick mark per snapshot and the
+// viewed position highlighted.
+func (r *Renderer) drawTimeline(input *InputState) {
+	tl := input.timeline
+	x0, x1 := float64(timelineLeft), float64(timelineRight)
+	y0, y1 := float64(timelineTop), float64(timelineTop+timelineHeight)
+
+	frame := [3]byte{80, 80, 80}
+	r.drawLine(x0, y0, x1, y0, frame)
+	r.drawLine(x0, y1, x1, y1, frame)
+	r.drawLine(x0, y0, x0, y1, frame)
+	r.drawLine(x1, y0, x1, y1, frame)
+
+	pos := input.timelinePos
+	if pos < 0 {
+		pos = tl.Len() - 1
+	}
+	span := float64(tl.Len() - 1)
+	for i := 0; i < tl.Len(); i++ {
+		x := x0 + (x1-x0)*float64(i)/span
+		c := [3]byte{60, 90, 130}
+		if i <= pos {
+			c = [3]byte{90, 150, 220}
+		}
+		r.drawLine(x, y0+4, x, y1-4, c)
+	}
+	cursor := x0 + (x1-x0)*float64(pos)/span
+	r.drawLine(cursor, y0-4, cursor, y1+4, [3]byte{255, 255, 255})
+	r.drawLine(cursor+1, y0-4, cursor+1, y1+4, [3]byte{255, 255, 255})
+}
+
+// drawTimelineLabel prints the viewed tick and the recorded range above the
+// scrubber on the HUD.
+func (r *Renderer) drawTimelineLabel(world *physics.World, input *InputState) {
+	tl := input.timeline
+	label := fmt.Sprintf("REWIND  tick %d  (history %d-%d)  [Left] [Right] Step  [P] Resume from here",
+		world.Tick(), tl.At(0).Tick, tl.At(tl.Len()-1).Tick)
+	if input.timelinePos < 0 {
+		label = fmt.Sprintf("REWIND  tick %d  (history %d-%d)  [Left] Step back  Drag bar to scrub",
+			world.Tick(), tl.At(0).Tick, tl.At(tl.Len()-1).Tick)
+	}
+	if world.Swarm().Len() > 0 {
+		label += "  (test particles are not rewound)"
+	}
+	ebitenutil.DebugPrintAt(r.hudImage, label, timelineLeft/hudScale, (timelineTop-20)/hudScale)
+}